@@ -0,0 +1,106 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package xlog
+
+import (
+	"io"
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// 日志输出目的地，可以为每个Appender配置Writer、Formatter、日志级别及过滤器，
+// 通过Logging.AddAppender添加，多个Appender可同时输出同一条日志
+type Appender interface {
+	// 判断是否输出参数级别的日志（线程安全）
+	IsEnabled(severityLevel Level) bool
+
+	// 输出日志条目，失败时返回*LoggingError或其他错误（线程安全）。
+	// entry及其KeyValues会被复用，仅在调用期间有效，异步输出时需使用entry.Clone()复制后保存
+	Append(entry *Entry) error
+}
+
+type AppenderOpt func(a *appender)
+
+type appender struct {
+	writer        io.Writer
+	formatter     Formatter
+	filter        Filter
+	colorFlag     int
+	timeFormatter func(t time.Time) string
+	level         Level
+}
+
+// 创建内置的Appender，默认输出所有级别，使用与Logging相同的内置格式（不带颜色）。
+// 注意Appender只能进一步过滤Logging输出的日志，日志级别需同时满足Logging的配置（Logging.SetSeverityLevel）。
+// Appender不会自动为输出的Writer加锁，如果需要加锁请使用LockedWriter
+func NewAppender(w io.Writer, opts ...AppenderOpt) *appender {
+	ret := &appender{
+		writer:        w,
+		colorFlag:     DisableColor,
+		timeFormatter: TimeFormat,
+		level:         Level(math.MaxInt32),
+	}
+	for _, v := range opts {
+		v(ret)
+	}
+	return ret
+}
+
+func (a *appender) IsEnabled(severityLevel Level) bool {
+	return Level(atomic.LoadInt32((*int32)(&a.level))) >= severityLevel
+}
+
+// 设置Appender的日志级别（线程安全）
+func (a *appender) SetSeverityLevel(severityLevel Level) {
+	atomic.StoreInt32((*int32)(&a.level), int32(severityLevel))
+}
+
+func (a *appender) Append(entry *Entry) error {
+	if a.filter != nil && a.filter.Filter(entry) == FilterDeny {
+		return nil
+	}
+	op, err := formatEntry(a.writer, a.formatter, a.colorFlag, a.timeFormatter, entry)
+	if err != nil {
+		return &LoggingError{Op: op, Level: entry.Level, Err: err}
+	}
+	return nil
+}
+
+// 配置Appender的Formatter，为nil时使用内置格式
+func SetAppenderFormatter(f Formatter) func(*appender) {
+	return func(a *appender) {
+		a.formatter = f
+	}
+}
+
+// 配置Appender的日志级别，低于该级别的将不被输出
+func SetAppenderLevel(severityLevel Level) func(*appender) {
+	return func(a *appender) {
+		a.level = severityLevel
+	}
+}
+
+// 配置Appender的过滤器，结果为FilterDeny时不输出
+func SetAppenderFilter(f Filter) func(*appender) {
+	return func(a *appender) {
+		a.filter = f
+	}
+}
+
+// 配置Appender内置格式的颜色标志，有AutoColor、DisableColor、ForceColor
+func SetAppenderColorFlag(flag int) func(*appender) {
+	return func(a *appender) {
+		a.colorFlag = flag
+	}
+}
+
+// 配置Appender内置格式的时间格式化函数
+func SetAppenderTimeFormatter(f func(t time.Time) string) func(*appender) {
+	return func(a *appender) {
+		a.timeFormatter = f
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package xlog
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// xlog本身的包路径，按包跳过调用者时总是跳过
+var xlogPackages = []string{
+	reflect.TypeOf(logging{}).PkgPath(),
+	reflect.TypeOf(logging{}).PkgPath() + "/xlogr",
+}
+
+// 按包路径及函数名称跳过调用栈帧，创建后不再修改
+type callerSkipper struct {
+	packages map[string]struct{}
+	// 以"/..."结尾的包路径，匹配包及其子包
+	prefixes []string
+	funcs    map[string]struct{}
+}
+
+func (s *callerSkipper) clone() *callerSkipper {
+	ret := &callerSkipper{
+		packages: map[string]struct{}{},
+		funcs:    map[string]struct{}{},
+	}
+	if s == nil {
+		for _, v := range xlogPackages {
+			ret.packages[v] = struct{}{}
+		}
+		return ret
+	}
+	for k := range s.packages {
+		ret.packages[k] = struct{}{}
+	}
+	ret.prefixes = append(ret.prefixes, s.prefixes...)
+	for k := range s.funcs {
+		ret.funcs[k] = struct{}{}
+	}
+	return ret
+}
+
+func (s *callerSkipper) skip(funcName string) bool {
+	pkg := funcPackage(funcName)
+	funcName = unescapeFuncName(funcName)
+	if _, ok := s.funcs[funcName]; ok {
+		return true
+	}
+	if _, ok := s.packages[pkg]; ok {
+		return true
+	}
+	for _, v := range s.prefixes {
+		if pkg == v || strings.HasPrefix(pkg, v+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// 获得第一个不需要跳过的调用栈帧
+func (s *callerSkipper) caller() (runtime.Frame, bool) {
+	pcs := make([]uintptr, MaxStackDepth)
+	// 跳过runtime.Callers及caller
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" && !s.skip(frame.Function) {
+			return frame, true
+		}
+		if !more {
+			return runtime.Frame{}, false
+		}
+	}
+}
+
+// 去掉调用栈开头需要跳过的帧
+func (s *callerSkipper) trim(stack StackTrace) StackTrace {
+	for i, f := range stack {
+		if !s.skip(f.Function) {
+			return stack[i:]
+		}
+	}
+	return stack
+}
+
+// 获得函数名称的包路径，如github.com/xfali/xlog.(*logging).Logf的包路径为github.com/xfali/xlog。
+// 运行时函数名称中包路径最后一段的'.'转义为"%2e"（如gopkg.in/yaml%2ev2.Marshal），
+// 因此最后一个'/'之后的第一个'.'为包路径的结尾，返回反转义后的包路径
+func funcPackage(funcName string) string {
+	slash := strings.LastIndexByte(funcName, '/')
+	dot := strings.IndexByte(funcName[slash+1:], '.')
+	if dot < 0 {
+		return unescapeFuncName(funcName)
+	}
+	return unescapeFuncName(funcName[:slash+1+dot])
+}
+
+// 还原运行时函数名称中转义的'.'
+func unescapeFuncName(funcName string) string {
+	if strings.Contains(funcName, "%2e") {
+		return strings.Replace(funcName, "%2e", ".", -1)
+	}
+	return funcName
+}
+
+// 配置按包路径跳过调用者，调用者为第一个不属于xlog及参数包的调用栈帧，开启后忽略调用深度（depth）。
+// 包路径以"/..."结尾时同时匹配子包，如："github.com/me/app/logutil/..."
+func SetCallerSkipPackages(pkgs ...string) func(*logging) {
+	return func(logging *logging) {
+		s := logging.callerSkip.clone()
+		for _, v := range pkgs {
+			if strings.HasSuffix(v, "/...") {
+				s.prefixes = append(s.prefixes, strings.TrimSuffix(v, "/..."))
+			} else {
+				s.packages[v] = struct{}{}
+			}
+		}
+		logging.callerSkip = s
+	}
+}
+
+// 配置跳过的函数（完整名称，如："github.com/me/app/logutil.(*Wrapper).Info"），
+// 同时开启按包路径跳过调用者，参见SetCallerSkipPackages
+func SetCallerSkipFunctions(funcs ...string) func(*logging) {
+	return func(logging *logging) {
+		s := logging.callerSkip.clone()
+		for _, v := range funcs {
+			s.funcs[v] = struct{}{}
+		}
+		logging.callerSkip = s
+	}
+}
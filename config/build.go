@@ -0,0 +1,314 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package config
+
+import (
+	"errors"
+	"github.com/xfali/xlog"
+	"github.com/xfali/xlog/writer"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 根据配置创建的日志组件
+type Setup struct {
+	Logging xlog.Logging
+	Factory xlog.LoggerFactory
+
+	closers []io.Closer
+}
+
+// 关闭配置创建的Writer并取消注册，忽略已由xlog.Shutdown关闭的Writer返回的os.ErrClosed
+func (s *Setup) Close() error {
+	var ret error
+	for _, c := range s.closers {
+		if w, ok := c.(io.Writer); ok {
+			xlog.UnregisterWriter(w)
+		}
+		if err := c.Close(); err != nil && !errors.Is(err, os.ErrClosed) && ret == nil {
+			ret = err
+		}
+	}
+	s.closers = nil
+	return ret
+}
+
+// 读取配置文件并创建日志组件，参见LoadFile及Config.Build
+func BuildFile(path string) (*Setup, error) {
+	c, err := LoadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return c.Build()
+}
+
+// 根据配置创建Logging的参数
+func (c *Config) LoggingOpts() ([]xlog.LoggingOpt, error) {
+	var opts []xlog.LoggingOpt
+	if c.Caller != "" {
+		flag, err := ParseCallerFlag(c.Caller)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, xlog.SetCallerFlag(flag))
+	}
+	if c.Color != "" {
+		flag, err := ParseColorFlag(c.Color)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, xlog.SetColorFlag(flag))
+	}
+	if c.FatalNoTrace {
+		opts = append(opts, xlog.SetFatalNoTrace(true))
+	}
+	if c.Formatter.TimeLayout != "" {
+		opts = append(opts, xlog.SetTimeFormatter(timeFormat(c.Formatter.TimeLayout)))
+	}
+	if len(c.Loggers) > 0 {
+		levels := make(map[string]xlog.Level, len(c.Loggers))
+		for k, v := range c.Loggers {
+			lv, err := xlog.ParseLevel(v)
+			if err != nil {
+				return nil, err
+			}
+			levels[k] = lv
+		}
+		opts = append(opts, xlog.SetLevelsByName(levels))
+	}
+	return opts, nil
+}
+
+// 根据配置创建Formatter，Type为空时返回nil
+func (c *FormatterConfig) Build() (xlog.Formatter, error) {
+	switch strings.ToLower(c.Type) {
+	case "":
+		return nil, nil
+	case "text":
+		f := &xlog.TextFormatter{
+			WithQuote: c.Quote,
+		}
+		if c.TimeLayout != "" {
+			f.TimeFormat = timeFormat(c.TimeLayout)
+		}
+		if c.SortKeys {
+			f.SortFunc = sort.Strings
+		}
+		return f, nil
+	case "json":
+		return &xlog.JsonFormatter{}, nil
+	}
+	return nil, errors.New("Unknown formatter type: " + c.Type)
+}
+
+// 根据配置创建Writer，返回的io.Closer为nil时表示无需关闭
+func (c *OutputConfig) Build() (io.Writer, io.Closer, error) {
+	var w io.Writer
+	var closer writer.Closer
+	switch strings.ToLower(c.Type) {
+	case "", "stdout":
+		w = os.Stdout
+	case "stderr":
+		w = os.Stderr
+	case "file":
+		if c.Path == "" {
+			return nil, nil, errors.New("Output path must be not empty ")
+		}
+		frequency, err := ParseRotateFrequency(c.RotateFrequency)
+		if err != nil {
+			return nil, nil, err
+		}
+		f := &writer.RotateFile{
+			Path:            c.Path,
+			MaxFileSize:     c.MaxFileSize,
+			RotateFrequency: frequency,
+		}
+		if c.Compress {
+			f.RotateFunc = writer.ZipLogsAsync
+		}
+		if err := f.Open(); err != nil {
+			return nil, nil, err
+		}
+		w, closer = f, f.Close
+	default:
+		return nil, nil, errors.New("Unknown output type: " + c.Type)
+	}
+
+	if c.Async != nil {
+		aw := writer.NewAsyncBufferWriter(w, closer, writer.Config{
+			FlushSize:     c.Async.FlushSize,
+			BufferSize:    c.Async.BufferSize,
+			FlushInterval: time.Duration(c.Async.FlushInterval),
+			Block:         c.Async.Block,
+		})
+		return aw, aw, nil
+	}
+	if closer != nil {
+		lw := &writer.LockedWriteCloser{W: w.(io.WriteCloser)}
+		return lw, lw, nil
+	}
+	return w, nil, nil
+}
+
+// 根据配置创建Logging及LoggerFactory，出错时已创建的Writer会被关闭
+func (c *Config) Build() (*Setup, error) {
+	opts, err := c.LoggingOpts()
+	if err != nil {
+		return nil, err
+	}
+	logging := xlog.NewLogging(opts...)
+	ret := &Setup{
+		Logging: logging,
+	}
+	if err := c.apply(ret); err != nil {
+		ret.Close()
+		return nil, err
+	}
+
+	if c.Factory.Mutable {
+		fac := xlog.NewMutableFactory(logging)
+		if c.Factory.SimplifyName {
+			fac.SimplifyNameFunc = xlog.SimplifyNameFirstLetter
+		}
+		ret.Factory = fac
+	} else {
+		fac := xlog.NewFactory(logging)
+		if c.Factory.SimplifyName {
+			fac.SimplifyNameFunc = xlog.SimplifyNameFirstLetter
+		}
+		ret.Factory = fac
+	}
+	return ret, nil
+}
+
+// 为已创建的Logging配置调用信息标志及颜色标志，创建Logging时使用LoggingOpts
+func (c *Config) applyFlags(logging xlog.Logging) error {
+	if c.Caller != "" {
+		flag, err := ParseCallerFlag(c.Caller)
+		if err != nil {
+			return err
+		}
+		logging.SetCallerFlag(flag)
+	}
+	if c.Color != "" {
+		flag, err := ParseColorFlag(c.Color)
+		if err != nil {
+			return err
+		}
+		logging.SetColorFlag(flag)
+	}
+	return nil
+}
+
+func (c *Config) apply(s *Setup) error {
+	if c.Level != "" {
+		lv, err := xlog.ParseLevel(c.Level)
+		if err != nil {
+			return err
+		}
+		s.Logging.SetSeverityLevel(lv)
+	}
+	f, err := c.Formatter.Build()
+	if err != nil {
+		return err
+	}
+	if f != nil {
+		s.Logging.SetFormatter(f)
+	}
+
+	for i := range c.Outputs {
+		out := &c.Outputs[i]
+		levels := make([]xlog.Level, 0, len(out.Levels))
+		for _, v := range out.Levels {
+			lv, err := xlog.ParseLevel(v)
+			if err != nil {
+				return err
+			}
+			levels = append(levels, lv)
+		}
+		w, closer, err := out.Build()
+		if err != nil {
+			return err
+		}
+		if closer != nil {
+			// SetOutput自动注册到xlog，xlog.Shutdown及FATAL日志退出前可同步
+			s.closers = append(s.closers, closer)
+		}
+		if len(levels) == 0 {
+			s.Logging.SetOutput(w)
+		} else {
+			for _, lv := range levels {
+				s.Logging.SetOutputBySeverity(lv, w)
+			}
+		}
+	}
+	return nil
+}
+
+// 解析调用信息标志，可选值：none、shortFile、longFile、shortFunc、longFunc、simpleFunc（不区分大小写），多个以'|'或','分隔
+func ParseCallerFlag(s string) (int, error) {
+	flag := 0
+	items := strings.FieldsFunc(s, func(r rune) bool {
+		return r == '|' || r == ','
+	})
+	for _, item := range items {
+		switch strings.ToLower(strings.TrimSpace(item)) {
+		case "none", "":
+		case "shortfile":
+			flag |= xlog.CallerShortFile
+		case "longfile":
+			flag |= xlog.CallerLongFile
+		case "shortfunc":
+			flag |= xlog.CallerShortFunc
+		case "longfunc":
+			flag |= xlog.CallerLongFunc
+		case "simplefunc":
+			flag |= xlog.CallerSimpleFunc
+		default:
+			return 0, errors.New("Unknown caller flag: " + item)
+		}
+	}
+	return flag, nil
+}
+
+// 解析颜色标志，可选值：auto、disable、force（不区分大小写）
+func ParseColorFlag(s string) (int, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "auto":
+		return xlog.AutoColor, nil
+	case "disable", "none", "false":
+		return xlog.DisableColor, nil
+	case "force", "true":
+		return xlog.ForceColor, nil
+	}
+	return 0, errors.New("Unknown color flag: " + s)
+}
+
+// 解析滚动频率，可选值：none、day、hour、minute、second（不区分大小写）或时间间隔如12h
+func ParseRotateFrequency(s string) (writer.RotateFrequency, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "none":
+		return writer.RotateNone, nil
+	case "day":
+		return writer.RotateEveryDay, nil
+	case "hour":
+		return writer.RotateEveryHour, nil
+	case "minute":
+		return writer.RotateEveryMinute, nil
+	case "second":
+		return writer.RotateEverySecond, nil
+	}
+	return time.ParseDuration(s)
+}
+
+func timeFormat(layout string) func(t time.Time) string {
+	return func(t time.Time) string {
+		return t.Format(layout)
+	}
+}
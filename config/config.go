@@ -0,0 +1,169 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 将配置数据解析到对象的函数，如json.Unmarshal、yaml.Unmarshal
+type UnmarshalFunc func(data []byte, v interface{}) error
+
+// 配置文件扩展名与解析函数的映射，默认支持json及yaml，可注册其他格式
+var Unmarshalers = map[string]UnmarshalFunc{
+	".json": json.Unmarshal,
+	".yaml": yaml.Unmarshal,
+	".yml":  yaml.Unmarshal,
+}
+
+// 日志配置
+type Config struct {
+	// 日志级别，如：INFO，为空时使用xlog.DefaultLevel
+	Level string `json:"level" yaml:"level"`
+	// 按日志名称配置的日志级别，如：{"db": "DEBUG", "db.pool": "WARN"}
+	Loggers map[string]string `json:"loggers" yaml:"loggers"`
+	// 调用信息标志，可选值：none、shortFile、longFile、shortFunc、longFunc、simpleFunc，多个以'|'分隔
+	Caller string `json:"caller" yaml:"caller"`
+	// 颜色标志，可选值：auto、disable、force
+	Color string `json:"color" yaml:"color"`
+	// 发生致命错误时是否不打印堆栈
+	FatalNoTrace bool `json:"fatalNoTrace" yaml:"fatalNoTrace"`
+	// 日志格式
+	Formatter FormatterConfig `json:"formatter" yaml:"formatter"`
+	// 日志输出，按顺序配置，相同级别后面的配置覆盖前面的配置
+	Outputs []OutputConfig `json:"outputs" yaml:"outputs"`
+	// LoggerFactory配置
+	Factory FactoryConfig `json:"factory" yaml:"factory"`
+}
+
+type FormatterConfig struct {
+	// 格式类型，可选值：text、json，为空时使用Logging内置的格式
+	Type string `json:"type" yaml:"type"`
+	// 时间格式，如：2006-01-02 15:04:05，为空时使用xlog.TimeFormat
+	TimeLayout string `json:"timeLayout" yaml:"timeLayout"`
+	// text格式是否为值添加引号
+	Quote bool `json:"quote" yaml:"quote"`
+	// text格式是否按key排序
+	SortKeys bool `json:"sortKeys" yaml:"sortKeys"`
+}
+
+type OutputConfig struct {
+	// 输出的日志级别，为空时输出所有级别
+	Levels []string `json:"levels" yaml:"levels"`
+	// 输出类型，可选值：stdout、stderr、file
+	Type string `json:"type" yaml:"type"`
+	// 文件路径，file类型有效
+	Path string `json:"path" yaml:"path"`
+	// 文件的大小阈值，超出后滚动文件，file类型有效
+	MaxFileSize int64 `json:"maxFileSize" yaml:"maxFileSize"`
+	// 滚动频率，可选值：none、day、hour、minute、second或时间间隔如12h，file类型有效
+	RotateFrequency string `json:"rotateFrequency" yaml:"rotateFrequency"`
+	// 是否压缩滚动的文件，file类型有效
+	Compress bool `json:"compress" yaml:"compress"`
+	// 异步缓存配置，为空时同步输出
+	Async *AsyncConfig `json:"async" yaml:"async"`
+}
+
+type AsyncConfig struct {
+	// 触发刷新的数据大小阈值
+	FlushSize int64 `json:"flushSize" yaml:"flushSize"`
+	// 异步缓存的大小
+	BufferSize int `json:"bufferSize" yaml:"bufferSize"`
+	// 触发刷新的时间间隔，如：500ms
+	FlushInterval Duration `json:"flushInterval" yaml:"flushInterval"`
+	// 缓存满时是否阻塞，为false时返回错误
+	Block bool `json:"block" yaml:"block"`
+}
+
+type FactoryConfig struct {
+	// 是否使用可变的LoggerFactory（Logger跟随Factory重置Logging）
+	Mutable bool `json:"mutable" yaml:"mutable"`
+	// 是否简化根据类型获得的Logger名称
+	SimplifyName bool `json:"simplifyName" yaml:"simplifyName"`
+}
+
+// 时间间隔，支持字符串（如："500ms"）及数字（纳秒）
+type Duration time.Duration
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		s, err := strconv.Unquote(string(data))
+		if err != nil {
+			return err
+		}
+		return d.UnmarshalText([]byte(s))
+	}
+	var v int64
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+func (d *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var v interface{}
+	if err := unmarshal(&v); err != nil {
+		return err
+	}
+	switch n := v.(type) {
+	case string:
+		return d.UnmarshalText([]byte(n))
+	case int:
+		*d = Duration(n)
+	case int64:
+		*d = Duration(n)
+	case uint64:
+		*d = Duration(n)
+	default:
+		return errors.New("Duration must be string or integer")
+	}
+	return nil
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// 使用参数解析函数解析配置
+func Parse(data []byte, unmarshal UnmarshalFunc) (*Config, error) {
+	ret := &Config{}
+	err := unmarshal(data, ret)
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// 读取配置文件，根据文件扩展名从Unmarshalers中选择解析函数
+func LoadFile(path string) (*Config, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	unmarshal, ok := Unmarshalers[ext]
+	if !ok {
+		return nil, errors.New("No unmarshaler for file: " + path)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data, unmarshal)
+}
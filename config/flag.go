@@ -0,0 +1,159 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package config
+
+import (
+	"flag"
+	"github.com/xfali/xlog"
+	"io"
+	"os"
+	"strings"
+)
+
+// 环境变量名称前缀，如：XLOG_LEVEL
+const EnvPrefix = "XLOG_"
+
+// 命令行参数名称前缀，如：-log.level
+const FlagPrefix = "log."
+
+// 命令行参数及环境变量配置，优先级：命令行参数 > 环境变量 > 默认值，
+// 未通过命令行参数或环境变量配置的项不会应用到Logging
+type Flags struct {
+	// 日志级别，对应-log.level、XLOG_LEVEL，仅在通过命令行参数或环境变量配置时生效
+	Level xlog.Level
+	// 日志格式，可选值：text、json，为空时使用Logging内置的格式，对应-log.format、XLOG_FORMAT
+	Format string
+	// 调用信息标志，参见ParseCallerFlag，对应-log.caller、XLOG_CALLER
+	Caller string
+	// 颜色标志，参见ParseColorFlag，对应-log.color、XLOG_COLOR
+	Color string
+	// 日志输出，可选值：stdout、stderr或文件路径，对应-log.output、XLOG_OUTPUT
+	Output string
+
+	levelSet bool
+	envErr   error
+}
+
+// 命令行参数-log.level，配置后覆盖环境变量XLOG_LEVEL（包括其解析错误）
+type levelFlag struct {
+	f *Flags
+}
+
+func (v *levelFlag) String() string {
+	if v.f == nil {
+		return ""
+	}
+	return v.f.Level.String()
+}
+
+func (v *levelFlag) Set(s string) error {
+	if err := v.f.Level.Set(s); err != nil {
+		return err
+	}
+	v.f.levelSet = true
+	v.f.envErr = nil
+	return nil
+}
+
+// 读取环境变量并在参数FlagSet中注册命令行参数，fs为nil时使用flag.CommandLine，
+// 需在FlagSet.Parse之后使用返回的Flags
+func BindFlags(fs *flag.FlagSet) *Flags {
+	if fs == nil {
+		fs = flag.CommandLine
+	}
+	ret := &Flags{
+		Level: xlog.DefaultLevel,
+	}
+	ret.LoadEnv()
+
+	fs.Var(&levelFlag{f: ret}, FlagPrefix+"level", "log level: TRACE, DEBUG, INFO, WARN, ERROR, PANIC, FATAL")
+	fs.StringVar(&ret.Format, FlagPrefix+"format", ret.Format, "log format: text, json, empty to use builtin format")
+	fs.StringVar(&ret.Caller, FlagPrefix+"caller", ret.Caller, "log caller flag: none, shortFile, longFile, shortFunc, longFunc, simpleFunc, joined with '|'")
+	fs.StringVar(&ret.Color, FlagPrefix+"color", ret.Color, "log color flag: auto, disable, force")
+	fs.StringVar(&ret.Output, FlagPrefix+"output", ret.Output, "log output: stdout, stderr or file path")
+	return ret
+}
+
+// 读取XLOG_LEVEL、XLOG_FORMAT、XLOG_CALLER、XLOG_COLOR、XLOG_OUTPUT环境变量，未配置的保持原值
+func (f *Flags) LoadEnv() {
+	if v, ok := os.LookupEnv(EnvPrefix + "LEVEL"); ok {
+		if err := f.Level.Set(v); err != nil {
+			f.envErr = err
+		} else {
+			f.levelSet = true
+		}
+	}
+	if v, ok := os.LookupEnv(EnvPrefix + "FORMAT"); ok {
+		f.Format = v
+	}
+	if v, ok := os.LookupEnv(EnvPrefix + "CALLER"); ok {
+		f.Caller = v
+	}
+	if v, ok := os.LookupEnv(EnvPrefix + "COLOR"); ok {
+		f.Color = v
+	}
+	if v, ok := os.LookupEnv(EnvPrefix + "OUTPUT"); ok {
+		f.Output = v
+	}
+}
+
+// 转换为Config
+func (f *Flags) Config() *Config {
+	ret := &Config{
+		Caller: f.Caller,
+		Color:  f.Color,
+		Formatter: FormatterConfig{
+			Type: f.Format,
+		},
+	}
+	if f.levelSet {
+		ret.Level = f.Level.String()
+	}
+	switch strings.ToLower(f.Output) {
+	case "":
+	case "stdout", "stderr":
+		ret.Outputs = []OutputConfig{{Type: f.Output}}
+	default:
+		ret.Outputs = []OutputConfig{{Type: "file", Path: f.Output}}
+	}
+	return ret
+}
+
+// 根据配置创建Logging的参数（调用信息标志及颜色标志）
+func (f *Flags) LoggingOpts() ([]xlog.LoggingOpt, error) {
+	if f.envErr != nil {
+		return nil, f.envErr
+	}
+	return f.Config().LoggingOpts()
+}
+
+// 为参数Logging配置日志级别、格式、调用信息标志、颜色标志及输出，仅应用已配置的项，
+// 返回的io.Closer用于关闭创建的文件输出
+func (f *Flags) Apply(logging xlog.Logging) (io.Closer, error) {
+	if f.envErr != nil {
+		return nil, f.envErr
+	}
+	c := f.Config()
+	if err := c.applyFlags(logging); err != nil {
+		return nil, err
+	}
+	s := &Setup{
+		Logging: logging,
+	}
+	if err := c.apply(s); err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// 根据配置创建Logging及LoggerFactory，参见Config.Build
+func (f *Flags) Build() (*Setup, error) {
+	if f.envErr != nil {
+		return nil, f.envErr
+	}
+	return f.Config().Build()
+}
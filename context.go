@@ -0,0 +1,113 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package xlog
+
+import (
+	"context"
+	"sync"
+)
+
+// 从context中提取日志附加信息的函数，没有信息时返回nil
+type ContextExtractor func(ctx context.Context) KeyValues
+
+type ctxFieldsKey struct{}
+
+var (
+	ctxExtractorLock sync.RWMutex
+	ctxExtractors    = []ContextExtractor{FieldsFromContext}
+)
+
+// 注册context信息提取函数（线程安全），默认已注册FieldsFromContext
+// 多个提取函数提取出相同的key时，后注册的覆盖先注册的
+func RegisterContextExtractor(extractors ...ContextExtractor) {
+	ctxExtractorLock.Lock()
+	defer ctxExtractorLock.Unlock()
+
+	for _, v := range extractors {
+		if v != nil {
+			ctxExtractors = append(ctxExtractors, v)
+		}
+	}
+}
+
+// 清除所有已注册的context信息提取函数（线程安全）
+func ClearContextExtractors() {
+	ctxExtractorLock.Lock()
+	defer ctxExtractorLock.Unlock()
+
+	ctxExtractors = nil
+}
+
+// 使用所有已注册的提取函数从context中提取日志附加信息，没有信息时返回nil
+func ExtractContext(ctx context.Context) KeyValues {
+	if ctx == nil {
+		return nil
+	}
+
+	ctxExtractorLock.RLock()
+	defer ctxExtractorLock.RUnlock()
+
+	var ret KeyValues
+	for _, extractor := range ctxExtractors {
+		kvs := extractor(ctx)
+		if kvs == nil || kvs.Len() == 0 {
+			continue
+		}
+		if ret == nil {
+			ret = NewKeyValues()
+		}
+		MergeKeyValues(ret, kvs)
+	}
+	return ret
+}
+
+// 将日志附加信息保存到context中，会附加父context中已保存的信息，如果相同则会覆盖
+// 配合默认注册的FieldsFromContext使用
+func NewContext(ctx context.Context, keyAndValues ...interface{}) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var kvs KeyValues
+	if parent, ok := ctx.Value(ctxFieldsKey{}).(KeyValues); ok {
+		kvs = parent.Clone()
+		kvs.Add(keyAndValues...)
+	} else {
+		kvs = NewKeyValues(keyAndValues...)
+	}
+	return context.WithValue(ctx, ctxFieldsKey{}, kvs)
+}
+
+// 获得通过NewContext保存到context中的日志附加信息
+func FieldsFromContext(ctx context.Context) KeyValues {
+	if kvs, ok := ctx.Value(ctxFieldsKey{}).(KeyValues); ok {
+		return kvs
+	}
+	return nil
+}
+
+// 创建根据context key提取信息的函数，提取的值使用参数name作为日志附加信息的key
+func ContextValueExtractor(ctxKey interface{}, name string) ContextExtractor {
+	return func(ctx context.Context) KeyValues {
+		v := ctx.Value(ctxKey)
+		if v == nil {
+			return nil
+		}
+		return NewKeyValues(name, v)
+	}
+}
+
+// 将ctx中提取的信息合并到keyValues中，注意keyValues不为nil时会被修改
+func mergeContext(ctx context.Context, keyValues KeyValues) KeyValues {
+	ctxKvs := ExtractContext(ctx)
+	if ctxKvs == nil {
+		return keyValues
+	}
+	if keyValues == nil {
+		return ctxKvs
+	}
+	MergeKeyValues(keyValues, ctxKvs)
+	return keyValues
+}
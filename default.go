@@ -6,6 +6,7 @@
 package xlog
 
 import (
+	"context"
 	"github.com/xfali/xlog/value"
 )
 
@@ -28,7 +29,7 @@ type xlog struct {
 
 func newLogger(logging Logging, fields KeyValues, name ...string) *xlog {
 	if fields == nil {
-		fields = NewKeyValues()
+		fields = NewFields()
 	}
 	var t string
 	if len(name) > 0 {
@@ -46,7 +47,7 @@ func newLogger(logging Logging, fields KeyValues, name ...string) *xlog {
 }
 
 func (l *xlog) DebugEnabled() bool {
-	return l.logging.IsEnabled(DEBUG)
+	return l.logging.IsEnabledByName(l.name, DEBUG)
 }
 
 func (l *xlog) Debug(args ...interface{}) {
@@ -61,8 +62,12 @@ func (l *xlog) Debugf(fmt string, args ...interface{}) {
 	l.logging.Logf(DEBUG, l.depth, l.fields, fmt, args...)
 }
 
+func (l *xlog) DebugFields(msg string, fields ...Field) {
+	l.logFields(DEBUG, msg, fields)
+}
+
 func (l *xlog) InfoEnabled() bool {
-	return l.logging.IsEnabled(INFO)
+	return l.logging.IsEnabledByName(l.name, INFO)
 }
 
 func (l *xlog) Info(args ...interface{}) {
@@ -77,8 +82,12 @@ func (l *xlog) Infof(fmt string, args ...interface{}) {
 	l.logging.Logf(INFO, l.depth, l.fields, fmt, args...)
 }
 
+func (l *xlog) InfoFields(msg string, fields ...Field) {
+	l.logFields(INFO, msg, fields)
+}
+
 func (l *xlog) WarnEnabled() bool {
-	return l.logging.IsEnabled(WARN)
+	return l.logging.IsEnabledByName(l.name, WARN)
 }
 
 func (l *xlog) Warn(args ...interface{}) {
@@ -93,8 +102,12 @@ func (l *xlog) Warnf(fmt string, args ...interface{}) {
 	l.logging.Logf(WARN, l.depth, l.fields, fmt, args...)
 }
 
+func (l *xlog) WarnFields(msg string, fields ...Field) {
+	l.logFields(WARN, msg, fields)
+}
+
 func (l *xlog) ErrorEnabled() bool {
-	return l.logging.IsEnabled(ERROR)
+	return l.logging.IsEnabledByName(l.name, ERROR)
 }
 
 func (l *xlog) Error(args ...interface{}) {
@@ -109,8 +122,12 @@ func (l *xlog) Errorf(fmt string, args ...interface{}) {
 	l.logging.Logf(ERROR, l.depth, l.fields, fmt, args...)
 }
 
+func (l *xlog) ErrorFields(msg string, fields ...Field) {
+	l.logFields(ERROR, msg, fields)
+}
+
 func (l *xlog) PanicEnabled() bool {
-	return l.logging.IsEnabled(PANIC)
+	return l.logging.IsEnabledByName(l.name, PANIC)
 }
 
 func (l *xlog) Panic(args ...interface{}) {
@@ -125,8 +142,12 @@ func (l *xlog) Panicf(fmt string, args ...interface{}) {
 	l.logging.Logf(PANIC, l.depth, l.fields, fmt, args...)
 }
 
+func (l *xlog) PanicFields(msg string, fields ...Field) {
+	l.logFields(PANIC, msg, fields)
+}
+
 func (l *xlog) FatalEnabled() bool {
-	return l.logging.IsEnabled(FATAL)
+	return l.logging.IsEnabledByName(l.name, FATAL)
 }
 
 func (l *xlog) Fatal(args ...interface{}) {
@@ -141,8 +162,46 @@ func (l *xlog) Fatalf(fmt string, args ...interface{}) {
 	l.logging.Logf(FATAL, l.depth, l.fields, fmt, args...)
 }
 
+func (l *xlog) FatalFields(msg string, fields ...Field) {
+	l.logFields(FATAL, msg, fields)
+}
+
+func (l *xlog) Log(level Level, args ...interface{}) {
+	l.logging.Log(level, l.depth, l.fields, args...)
+}
+
+func (l *xlog) Logln(level Level, args ...interface{}) {
+	l.logging.Logln(level, l.depth, l.fields, args...)
+}
+
+func (l *xlog) Logf(level Level, fmt string, args ...interface{}) {
+	l.logging.Logf(level, l.depth, l.fields, fmt, args...)
+}
+
+func (l *xlog) Recover(opts ...RecoverOpt) {
+	if v := recover(); v != nil {
+		handlePanic(l.logFields, v, opts)
+	}
+}
+
+func (l *xlog) logFields(level Level, msg string, fields []Field) {
+	logging := l.logging
+	if !logging.IsEnabledByName(l.name, level) {
+		return
+	}
+	if len(fields) == 0 {
+		logging.Logln(level, l.depth+1, l.fields, msg)
+		return
+	}
+	kvs := mergeCallFields(l.fields, fields)
+	logging.Logln(level, l.depth+1, kvs, msg)
+	if borrowsKeyValues(logging) {
+		putFields(kvs)
+	}
+}
+
 func (l *xlog) IsEnabled(severityLevel Level) bool {
-	return l.logging.IsEnabled(severityLevel)
+	return l.logging.IsEnabledByName(l.name, severityLevel)
 }
 
 func (l *xlog) WithName(name string) Logger {
@@ -171,6 +230,17 @@ func (l *xlog) WithFields(keyAndValues ...interface{}) Logger {
 	return ret
 }
 
+func (l *xlog) With(fields ...Field) Logger {
+	if l == nil {
+		return nil
+	}
+	ret := newLogger(l.logging, l.fields.Clone(), l.name)
+	addFields(ret.fields, fields)
+	ret.depth = l.depth
+
+	return ret
+}
+
 func (l *xlog) WithDepth(depth int) Logger {
 	if l == nil {
 		return nil
@@ -181,6 +251,16 @@ func (l *xlog) WithDepth(depth int) Logger {
 	return ret
 }
 
+func (l *xlog) WithContext(ctx context.Context) Logger {
+	if l == nil {
+		return nil
+	}
+	ret := newLogger(l.logging, mergeContext(ctx, l.fields.Clone()), l.name)
+	ret.depth = l.depth
+
+	return ret
+}
+
 type mutableLog struct {
 	logging value.Value
 	depth   int
@@ -190,7 +270,7 @@ type mutableLog struct {
 
 func newMutableLogger(logging value.Value, fields KeyValues, name ...string) *mutableLog {
 	if fields == nil {
-		fields = NewKeyValues()
+		fields = NewFields()
 	}
 	var t string
 	if len(name) > 0 {
@@ -213,7 +293,7 @@ func (l *mutableLog) getLogging() Logging {
 }
 
 func (l *mutableLog) DebugEnabled() bool {
-	return l.getLogging().IsEnabled(DEBUG)
+	return l.getLogging().IsEnabledByName(l.name, DEBUG)
 }
 
 func (l *mutableLog) Debug(args ...interface{}) {
@@ -228,8 +308,12 @@ func (l *mutableLog) Debugf(fmt string, args ...interface{}) {
 	l.getLogging().Logf(DEBUG, l.depth, l.fields, fmt, args...)
 }
 
+func (l *mutableLog) DebugFields(msg string, fields ...Field) {
+	l.logFields(DEBUG, msg, fields)
+}
+
 func (l *mutableLog) InfoEnabled() bool {
-	return l.getLogging().IsEnabled(INFO)
+	return l.getLogging().IsEnabledByName(l.name, INFO)
 }
 
 func (l *mutableLog) Info(args ...interface{}) {
@@ -244,8 +328,12 @@ func (l *mutableLog) Infof(fmt string, args ...interface{}) {
 	l.getLogging().Logf(INFO, l.depth, l.fields, fmt, args...)
 }
 
+func (l *mutableLog) InfoFields(msg string, fields ...Field) {
+	l.logFields(INFO, msg, fields)
+}
+
 func (l *mutableLog) WarnEnabled() bool {
-	return l.getLogging().IsEnabled(WARN)
+	return l.getLogging().IsEnabledByName(l.name, WARN)
 }
 
 func (l *mutableLog) Warn(args ...interface{}) {
@@ -260,8 +348,12 @@ func (l *mutableLog) Warnf(fmt string, args ...interface{}) {
 	l.getLogging().Logf(WARN, l.depth, l.fields, fmt, args...)
 }
 
+func (l *mutableLog) WarnFields(msg string, fields ...Field) {
+	l.logFields(WARN, msg, fields)
+}
+
 func (l *mutableLog) ErrorEnabled() bool {
-	return l.getLogging().IsEnabled(ERROR)
+	return l.getLogging().IsEnabledByName(l.name, ERROR)
 }
 
 func (l *mutableLog) Error(args ...interface{}) {
@@ -276,8 +368,12 @@ func (l *mutableLog) Errorf(fmt string, args ...interface{}) {
 	l.getLogging().Logf(ERROR, l.depth, l.fields, fmt, args...)
 }
 
+func (l *mutableLog) ErrorFields(msg string, fields ...Field) {
+	l.logFields(ERROR, msg, fields)
+}
+
 func (l *mutableLog) PanicEnabled() bool {
-	return l.getLogging().IsEnabled(PANIC)
+	return l.getLogging().IsEnabledByName(l.name, PANIC)
 }
 
 func (l *mutableLog) Panic(args ...interface{}) {
@@ -292,8 +388,12 @@ func (l *mutableLog) Panicf(fmt string, args ...interface{}) {
 	l.getLogging().Logf(PANIC, l.depth, l.fields, fmt, args...)
 }
 
+func (l *mutableLog) PanicFields(msg string, fields ...Field) {
+	l.logFields(PANIC, msg, fields)
+}
+
 func (l *mutableLog) FatalEnabled() bool {
-	return l.getLogging().IsEnabled(FATAL)
+	return l.getLogging().IsEnabledByName(l.name, FATAL)
 }
 
 func (l *mutableLog) Fatal(args ...interface{}) {
@@ -308,8 +408,46 @@ func (l *mutableLog) Fatalf(fmt string, args ...interface{}) {
 	l.getLogging().Logf(FATAL, l.depth, l.fields, fmt, args...)
 }
 
+func (l *mutableLog) FatalFields(msg string, fields ...Field) {
+	l.logFields(FATAL, msg, fields)
+}
+
+func (l *mutableLog) Log(level Level, args ...interface{}) {
+	l.getLogging().Log(level, l.depth, l.fields, args...)
+}
+
+func (l *mutableLog) Logln(level Level, args ...interface{}) {
+	l.getLogging().Logln(level, l.depth, l.fields, args...)
+}
+
+func (l *mutableLog) Logf(level Level, fmt string, args ...interface{}) {
+	l.getLogging().Logf(level, l.depth, l.fields, fmt, args...)
+}
+
+func (l *mutableLog) Recover(opts ...RecoverOpt) {
+	if v := recover(); v != nil {
+		handlePanic(l.logFields, v, opts)
+	}
+}
+
+func (l *mutableLog) logFields(level Level, msg string, fields []Field) {
+	logging := l.getLogging()
+	if !logging.IsEnabledByName(l.name, level) {
+		return
+	}
+	if len(fields) == 0 {
+		logging.Logln(level, l.depth+1, l.fields, msg)
+		return
+	}
+	kvs := mergeCallFields(l.fields, fields)
+	logging.Logln(level, l.depth+1, kvs, msg)
+	if borrowsKeyValues(logging) {
+		putFields(kvs)
+	}
+}
+
 func (l *mutableLog) IsEnabled(severityLevel Level) bool {
-	return l.getLogging().IsEnabled(severityLevel)
+	return l.getLogging().IsEnabledByName(l.name, severityLevel)
 }
 
 func (l *mutableLog) WithName(name string) Logger {
@@ -338,6 +476,17 @@ func (l *mutableLog) WithFields(keyAndValues ...interface{}) Logger {
 	return ret
 }
 
+func (l *mutableLog) With(fields ...Field) Logger {
+	if l == nil {
+		return nil
+	}
+	ret := newMutableLogger(l.logging, l.fields.Clone(), l.name)
+	addFields(ret.fields, fields)
+	ret.depth = l.depth
+
+	return ret
+}
+
 func (l *mutableLog) WithDepth(depth int) Logger {
 	if l == nil {
 		return nil
@@ -347,3 +496,49 @@ func (l *mutableLog) WithDepth(depth int) Logger {
 
 	return ret
 }
+
+func (l *mutableLog) WithContext(ctx context.Context) Logger {
+	if l == nil {
+		return nil
+	}
+	ret := newMutableLogger(l.logging, mergeContext(ctx, l.fields.Clone()), l.name)
+	ret.depth = l.depth
+
+	return ret
+}
+
+// 获得xlog创建的Logger当前使用的Logging
+func getLoggerLogging(logger Logger) (Logging, bool) {
+	switch l := logger.(type) {
+	case *xlog:
+		return l.logging, true
+	case *mutableLog:
+		return l.getLogging(), true
+	}
+	return nil, false
+}
+
+// 使用参数Logging创建保留名称、附加信息及调用深度的Logger，logger必须为xlog创建的Logger
+func rebindLogger(logger Logger, logging Logging) (Logger, bool) {
+	switch l := logger.(type) {
+	case *xlog:
+		ret := newLogger(logging, l.fields.Clone(), l.name)
+		ret.depth = l.depth
+		return ret, true
+	case *mutableLog:
+		ret := newLogger(logging, l.fields.Clone(), l.name)
+		ret.depth = l.depth
+		return ret, true
+	}
+	return nil, false
+}
+
+// 合并Logger及调用时的附加信息到池中获得的Fields，Logging调用返回后可以通过putFields放回，参见borrowsKeyValues
+func mergeCallFields(keyValues KeyValues, fields []Field) *Fields {
+	ret := getFields()
+	if keyValues != nil {
+		MergeKeyValues(ret, keyValues)
+	}
+	ret.AddFields(fields...)
+	return ret
+}
@@ -0,0 +1,50 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package xlog
+
+import (
+	"time"
+)
+
+// 日志条目，供Appender、Filter使用，会被复用，不应在调用之外保存（需要保存时使用Clone）。
+// 注意Logging的过滤器在格式化之前执行，此时Time、Caller、Stack为空
+type Entry struct {
+	// 日志时间
+	Time time.Time
+	// 日志级别
+	Level Level
+	// 日志名称，即KeyValues中KeyName的值
+	Name string
+	// 调用信息
+	Caller string
+	// 附加的日志内容
+	KeyValues KeyValues
+	// 调用栈，仅在日志级别达到SetStackTraceLevel配置的级别时有值
+	Stack StackTrace
+
+	msg     string
+	msgFunc func() string
+}
+
+// 获得日志内容，在Logging的过滤器中首次调用时才格式化
+func (e *Entry) Message() string {
+	if e.msgFunc != nil {
+		e.msg = e.msgFunc()
+		e.msgFunc = nil
+	}
+	return e.msg
+}
+
+// 复制日志条目，包括日志内容及附加的日志内容，复制的条目可在调用之外保存
+func (e *Entry) Clone() *Entry {
+	ret := *e
+	ret.msg = e.Message()
+	ret.msgFunc = nil
+	if e.KeyValues != nil {
+		ret.KeyValues = e.KeyValues.Clone()
+	}
+	return &ret
+}
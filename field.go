@@ -0,0 +1,342 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package xlog
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+type FieldType uint8
+
+const (
+	// 未知类型，值为nil
+	UnknownType FieldType = iota
+	// string类型，值保存在String
+	StringType
+	// 有符号整数类型，值保存在Integer
+	Int64Type
+	// 无符号整数类型，值保存在Integer
+	Uint64Type
+	// 浮点数类型，值以bits保存在Integer
+	Float64Type
+	// float32类型，值以bits保存在Integer，按32位精度格式化
+	Float32Type
+	// 布尔类型，值保存在Integer
+	BoolType
+	// time.Duration类型，值保存在Integer
+	DurationType
+	// time.Time类型，UnixNano保存在Integer，Location保存在Interface
+	TimeType
+	// error类型，值保存在Interface
+	ErrorType
+	// 其他类型，值保存在Interface
+	AnyType
+)
+
+// 强类型的日志附加信息，基础类型的值不会被转换为interface{}，内置的Formatter无需反射即可格式化
+type Field struct {
+	Key       string
+	Type      FieldType
+	Integer   int64
+	String    string
+	Interface interface{}
+}
+
+var (
+	minTimeField = time.Unix(0, math.MinInt64)
+	maxTimeField = time.Unix(0, math.MaxInt64)
+)
+
+func String(key string, val string) Field {
+	return Field{Key: key, Type: StringType, String: val}
+}
+
+func Int(key string, val int) Field {
+	return Int64(key, int64(val))
+}
+
+func Int32(key string, val int32) Field {
+	return Int64(key, int64(val))
+}
+
+func Int64(key string, val int64) Field {
+	return Field{Key: key, Type: Int64Type, Integer: val}
+}
+
+func Uint(key string, val uint) Field {
+	return Uint64(key, uint64(val))
+}
+
+func Uint32(key string, val uint32) Field {
+	return Uint64(key, uint64(val))
+}
+
+func Uint64(key string, val uint64) Field {
+	return Field{Key: key, Type: Uint64Type, Integer: int64(val)}
+}
+
+func Float32(key string, val float32) Field {
+	return Field{Key: key, Type: Float32Type, Integer: int64(math.Float32bits(val))}
+}
+
+func Float64(key string, val float64) Field {
+	return Field{Key: key, Type: Float64Type, Integer: int64(math.Float64bits(val))}
+}
+
+func Bool(key string, val bool) Field {
+	var i int64
+	if val {
+		i = 1
+	}
+	return Field{Key: key, Type: BoolType, Integer: i}
+}
+
+func Duration(key string, val time.Duration) Field {
+	return Field{Key: key, Type: DurationType, Integer: int64(val)}
+}
+
+// 超出UnixNano表示范围的时间使用AnyType保存
+func Time(key string, val time.Time) Field {
+	if val.Before(minTimeField) || val.After(maxTimeField) {
+		return Field{Key: key, Type: AnyType, Interface: val}
+	}
+	return Field{Key: key, Type: TimeType, Integer: val.UnixNano(), Interface: val.Location()}
+}
+
+// 使用KeyError作为key的error信息
+func Err(err error) Field {
+	return NamedErr(KeyError, err)
+}
+
+func NamedErr(key string, err error) Field {
+	if err == nil {
+		return Field{Key: key, Type: UnknownType}
+	}
+	return Field{Key: key, Type: ErrorType, Interface: err}
+}
+
+// 任意类型的日志附加信息，如果是已知的基础类型则转换为对应的强类型Field
+func Any(key string, val interface{}) Field {
+	switch v := val.(type) {
+	case nil:
+		return Field{Key: key, Type: UnknownType}
+	case string:
+		return String(key, v)
+	case int:
+		return keepValue(Int(key, v), val)
+	case int32:
+		return keepValue(Int32(key, v), val)
+	case int64:
+		return Int64(key, v)
+	case uint:
+		return keepValue(Uint(key, v), val)
+	case uint32:
+		return keepValue(Uint32(key, v), val)
+	case uint64:
+		return Uint64(key, v)
+	case float32:
+		return Float32(key, v)
+	case float64:
+		return Float64(key, v)
+	case bool:
+		return Bool(key, v)
+	case time.Duration:
+		return Duration(key, v)
+	case time.Time:
+		return Time(key, v)
+	case error:
+		return NamedErr(key, v)
+	default:
+		return Field{Key: key, Type: AnyType, Interface: val}
+	}
+}
+
+// 保存Any的参数，Value返回原类型的值（如int）
+func keepValue(f Field, val interface{}) Field {
+	f.Interface = val
+	return f
+}
+
+// 获得Field的值，注意基础类型会被转换为interface{}，通过Any（包括KeyValues.Add）添加的整数保持原类型
+func (f Field) Value() interface{} {
+	switch f.Type {
+	case StringType:
+		return f.String
+	case Int64Type:
+		if f.Interface != nil {
+			return f.Interface
+		}
+		return f.Integer
+	case Uint64Type:
+		if f.Interface != nil {
+			return f.Interface
+		}
+		return uint64(f.Integer)
+	case Float64Type:
+		return math.Float64frombits(uint64(f.Integer))
+	case Float32Type:
+		return math.Float32frombits(uint32(f.Integer))
+	case BoolType:
+		return f.Integer == 1
+	case DurationType:
+		return time.Duration(f.Integer)
+	case TimeType:
+		return f.time()
+	case ErrorType, AnyType:
+		return f.Interface
+	default:
+		return nil
+	}
+}
+
+// 浮点数的值及格式化使用的精度（bitSize）
+func (f *Field) float() (float64, int) {
+	if f.Type == Float32Type {
+		return float64(math.Float32frombits(uint32(f.Integer))), 32
+	}
+	return math.Float64frombits(uint64(f.Integer)), 64
+}
+
+func (f *Field) time() time.Time {
+	t := time.Unix(0, f.Integer)
+	if loc, ok := f.Interface.(*time.Location); ok && loc != nil {
+		t = t.In(loc)
+	}
+	return t
+}
+
+// 由Field组成的KeyValues实现，*Fields实现了KeyValues接口，相同的key会覆盖
+type Fields []Field
+
+func NewFields(fields ...Field) *Fields {
+	ret := make(Fields, 0, len(fields))
+	ret.AddFields(fields...)
+	return &ret
+}
+
+// 添加强类型的日志附加信息，如果key相同则覆盖
+func (f *Fields) AddFields(fields ...Field) {
+	for _, v := range fields {
+		if i := f.index(v.Key); i != -1 {
+			(*f)[i] = v
+		} else {
+			*f = append(*f, v)
+		}
+	}
+}
+
+func (f *Fields) Add(keyAndValues ...interface{}) error {
+	size := len(keyAndValues)
+	if size == 0 {
+		return nil
+	}
+	var idx int
+	for i := 0; i < size; i++ {
+		if i%2 == 0 {
+			if keyAndValues[i] == nil {
+				return errors.New("Key must be not nil ")
+			}
+			s, ok := keyAndValues[i].(string)
+			if !ok {
+				return errors.New("Key must be string ")
+			}
+			idx = f.index(s)
+			if idx == -1 {
+				*f = append(*f, Field{Key: s, Type: UnknownType})
+				idx = len(*f) - 1
+			}
+		} else {
+			(*f)[idx] = Any((*f)[idx].Key, keyAndValues[i])
+		}
+	}
+	return nil
+}
+
+func (f Fields) GetAll() map[string]interface{} {
+	ret := make(map[string]interface{}, len(f))
+	for i := range f {
+		ret[f[i].Key] = f[i].Value()
+	}
+	return ret
+}
+
+func (f Fields) Keys() []string {
+	ret := make([]string, len(f))
+	for i := range f {
+		ret[i] = f[i].Key
+	}
+	return ret
+}
+
+func (f Fields) Get(key string) interface{} {
+	if i := f.index(key); i != -1 {
+		return f[i].Value()
+	}
+	return nil
+}
+
+func (f *Fields) Remove(key string) error {
+	i := f.index(key)
+	if i == -1 {
+		return errors.New("Key not found ")
+	}
+	*f = append((*f)[:i], (*f)[i+1:]...)
+	return nil
+}
+
+func (f Fields) Len() int {
+	return len(f)
+}
+
+func (f Fields) Iterator() Iterator {
+	return &fieldsIterator{
+		fields: f,
+		cur:    0,
+	}
+}
+
+func (f Fields) Clone() KeyValues {
+	ret := make(Fields, len(f))
+	copy(ret, f)
+	return &ret
+}
+
+func (f Fields) index(key string) int {
+	for i := range f {
+		if f[i].Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+type fieldsIterator struct {
+	fields Fields
+	cur    int
+}
+
+func (c *fieldsIterator) HasNext() bool {
+	return c.cur < len(c.fields)
+}
+
+func (c *fieldsIterator) Next() (string, interface{}) {
+	v := c.fields[c.cur]
+	c.cur++
+	return v.Key, v.Value()
+}
+
+// 将Field添加到KeyValues中，如果是*Fields则不会转换类型
+func addFields(keyValues KeyValues, fields []Field) {
+	if fs, ok := keyValues.(*Fields); ok {
+		fs.AddFields(fields...)
+		return
+	}
+	for _, v := range fields {
+		keyValues.Add(v.Key, v.Value())
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package xlog
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// 过滤结果
+type FilterResult int
+
+const (
+	// 不做判断，交给后续规则处理
+	FilterNeutral FilterResult = iota
+	// 输出日志
+	FilterAccept
+	// 丢弃日志
+	FilterDeny
+)
+
+// 日志过滤器
+type Filter interface {
+	// 判断日志条目是否输出（线程安全）
+	Filter(entry *Entry) FilterResult
+}
+
+type FilterFunc func(entry *Entry) FilterResult
+
+func (f FilterFunc) Filter(entry *Entry) FilterResult {
+	return f(entry)
+}
+
+// 将过滤器的FilterAccept结果保留，其他结果转换为FilterNeutral，
+// 如：AcceptOnMatch(LevelFilter(ERROR))表示ERROR级别的日志直接输出，其他交给后续过滤器处理
+func AcceptOnMatch(f Filter) Filter {
+	return FilterFunc(func(entry *Entry) FilterResult {
+		if f.Filter(entry) == FilterAccept {
+			return FilterAccept
+		}
+		return FilterNeutral
+	})
+}
+
+// 将过滤器的FilterAccept结果转换为FilterDeny，其他结果转换为FilterNeutral，
+// 如：DenyOnMatch(NameFilter("thirdparty"))表示丢弃thirdparty及其子名称的日志
+func DenyOnMatch(f Filter) Filter {
+	return FilterFunc(func(entry *Entry) FilterResult {
+		if f.Filter(entry) == FilterAccept {
+			return FilterDeny
+		}
+		return FilterNeutral
+	})
+}
+
+// 组合过滤器（三值逻辑与）：任意结果为FilterDeny时返回FilterDeny，全部为FilterAccept时返回FilterAccept，否则返回FilterNeutral
+func And(filters ...Filter) Filter {
+	return FilterFunc(func(entry *Entry) FilterResult {
+		ret := FilterAccept
+		for _, f := range filters {
+			switch f.Filter(entry) {
+			case FilterDeny:
+				return FilterDeny
+			case FilterNeutral:
+				ret = FilterNeutral
+			}
+		}
+		return ret
+	})
+}
+
+// 组合过滤器（三值逻辑或）：任意结果为FilterAccept时返回FilterAccept，全部为FilterDeny时返回FilterDeny，否则返回FilterNeutral
+func Or(filters ...Filter) Filter {
+	return FilterFunc(func(entry *Entry) FilterResult {
+		ret := FilterDeny
+		for _, f := range filters {
+			switch f.Filter(entry) {
+			case FilterAccept:
+				return FilterAccept
+			case FilterNeutral:
+				ret = FilterNeutral
+			}
+		}
+		return ret
+	})
+}
+
+// 反转过滤器结果：FilterAccept与FilterDeny互换，FilterNeutral不变
+func Not(f Filter) Filter {
+	return FilterFunc(func(entry *Entry) FilterResult {
+		switch f.Filter(entry) {
+		case FilterAccept:
+			return FilterDeny
+		case FilterDeny:
+			return FilterAccept
+		}
+		return FilterNeutral
+	})
+}
+
+// 按顺序执行过滤器，遇到FilterAccept或FilterDeny时返回该结果，否则返回FilterNeutral
+func Chain(filters ...Filter) Filter {
+	return FilterFunc(func(entry *Entry) FilterResult {
+		return filterChain(filters, entry)
+	})
+}
+
+func filterChain(filters []Filter, entry *Entry) FilterResult {
+	for _, f := range filters {
+		if ret := f.Filter(entry); ret != FilterNeutral {
+			return ret
+		}
+	}
+	return FilterNeutral
+}
+
+func matchResult(match bool) FilterResult {
+	if match {
+		return FilterAccept
+	}
+	return FilterDeny
+}
+
+// 日志级别为参数级别之一时返回FilterAccept，否则返回FilterDeny
+func LevelFilter(levels ...Level) Filter {
+	return FilterFunc(func(entry *Entry) FilterResult {
+		for _, lv := range levels {
+			if entry.Level == lv {
+				return FilterAccept
+			}
+		}
+		return FilterDeny
+	})
+}
+
+// 日志级别不低于参数级别（同样或更严重）时返回FilterAccept，否则返回FilterDeny
+func ThresholdFilter(severityLevel Level) Filter {
+	return FilterFunc(func(entry *Entry) FilterResult {
+		return matchResult(entry.Level <= severityLevel)
+	})
+}
+
+// 日志名称为参数名称之一或其子名称（以'.'分隔）时返回FilterAccept，否则返回FilterDeny
+func NameFilter(names ...string) Filter {
+	tree := make(levelTree, len(names))
+	for _, v := range names {
+		tree[v] = 0
+	}
+	return FilterFunc(func(entry *Entry) FilterResult {
+		_, ok := tree.find(entry.Name)
+		return matchResult(ok)
+	})
+}
+
+// 附加的日志内容中key对应的值与参数value相等（按fmt.Sprint结果比较，以兼容不同的数值类型）时返回FilterAccept，否则返回FilterDeny
+func FieldFilter(key string, value interface{}) Filter {
+	expect := fmt.Sprint(value)
+	return FieldFuncFilter(key, func(v interface{}) bool {
+		return fmt.Sprint(v) == expect
+	})
+}
+
+// 附加的日志内容中存在key且其值满足match时返回FilterAccept，否则返回FilterDeny
+func FieldFuncFilter(key string, match func(v interface{}) bool) Filter {
+	return FilterFunc(func(entry *Entry) FilterResult {
+		if entry.KeyValues == nil {
+			return FilterDeny
+		}
+		v := entry.KeyValues.Get(key)
+		if v == nil {
+			return FilterDeny
+		}
+		return matchResult(match(v))
+	})
+}
+
+// 日志内容匹配参数正则表达式时返回FilterAccept，否则返回FilterDeny
+func MessageFilter(re *regexp.Regexp) Filter {
+	return FilterFunc(func(entry *Entry) FilterResult {
+		return matchResult(re.MatchString(entry.Message()))
+	})
+}
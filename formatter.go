@@ -6,12 +6,14 @@
 package xlog
 
 import (
-	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"math"
+	"strconv"
 	"time"
+	"unicode/utf8"
 )
 
 type Iterator interface {
@@ -157,6 +159,10 @@ func MergeKeyValues(keyValues ...KeyValues) (KeyValues, error) {
 			if tmp == nil {
 				continue
 			}
+			if fs, ok := tmp.(*Fields); ok {
+				addFields(kvs, *fs)
+				continue
+			}
 			keys := tmp.Keys()
 			for _, k := range keys {
 				err := kvs.Add(k, tmp.Get(k))
@@ -175,28 +181,115 @@ type TextFormatter struct {
 	SortFunc   func([]string)
 }
 
+// 内置Formatter的实现，格式化到缓存而不直接写入Writer，返回的缓存未增加数据时不输出
+type bufferFormatter interface {
+	appendFormat(buf []byte, keyValues KeyValues) ([]byte, error)
+}
+
+// 使用池化的缓存格式化并输出
+func formatToWriter(f bufferFormatter, writer io.Writer, keyValues KeyValues) error {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	var err error
+	buf.b, err = f.appendFormat(buf.b, keyValues)
+	if err != nil || len(buf.b) == 0 {
+		return err
+	}
+	_, err = writer.Write(buf.b)
+	return err
+}
+
 func (f *TextFormatter) Format(writer io.Writer, keyValues KeyValues) error {
+	return formatToWriter(f, writer, keyValues)
+}
+
+func (f *TextFormatter) appendFormat(buf []byte, keyValues KeyValues) ([]byte, error) {
+	if fs, ok := keyValues.(*Fields); ok && f.SortFunc == nil {
+		return f.appendFields(buf, *fs), nil
+	}
 	keys := keyValues.Keys()
 	if len(keys) == 0 {
-		return nil
+		return buf, nil
 	}
 
 	if f.SortFunc != nil {
 		f.SortFunc(keys)
 	}
 
-	buf := bytes.Buffer{}
 	for _, k := range keys {
-		buf.WriteString(k)
-		buf.WriteByte('=')
-		buf.WriteString(f.formatValue(keyValues.Get(k)))
-		buf.WriteByte(' ')
+		buf = append(buf, k...)
+		buf = append(buf, '=')
+		buf = append(buf, f.formatValue(keyValues.Get(k))...)
+		buf = append(buf, ' ')
 	}
-	if buf.Cap() == 0 || buf.Bytes()[buf.Len()-1] != '\n' {
-		buf.WriteByte('\n')
+	if buf[len(buf)-1] != '\n' {
+		buf = append(buf, '\n')
+	}
+	return buf, nil
+}
+
+func (f *TextFormatter) appendFields(buf []byte, fields Fields) []byte {
+	if len(fields) == 0 {
+		return buf
+	}
+
+	for i := range fields {
+		buf = append(buf, fields[i].Key...)
+		buf = append(buf, '=')
+		buf = f.appendField(buf, &fields[i])
+		buf = append(buf, ' ')
+	}
+	if buf[len(buf)-1] != '\n' {
+		buf = append(buf, '\n')
+	}
+	return buf
+}
+
+func (f *TextFormatter) appendField(buf []byte, field *Field) []byte {
+	if f.WithQuote {
+		return strconv.AppendQuote(buf, f.fieldString(field))
+	}
+	switch field.Type {
+	case StringType:
+		return append(buf, field.String...)
+	case Int64Type:
+		return strconv.AppendInt(buf, field.Integer, 10)
+	case Uint64Type:
+		return strconv.AppendUint(buf, uint64(field.Integer), 10)
+	case Float64Type, Float32Type:
+		v, bitSize := field.float()
+		return strconv.AppendFloat(buf, v, 'g', -1, bitSize)
+	case BoolType:
+		return strconv.AppendBool(buf, field.Integer == 1)
+	case TimeType:
+		if f.TimeFormat != nil {
+			return append(buf, f.TimeFormat(field.time())...)
+		}
+		return appendTimeString(buf, field.time())
+	default:
+		return append(buf, f.fieldString(field)...)
+	}
+}
+
+// 与time.Time.String()相同（不包括单调时钟）
+func appendTimeString(buf []byte, t time.Time) []byte {
+	return t.AppendFormat(buf, "2006-01-02 15:04:05.999999999 -0700 MST")
+}
+
+func (f *TextFormatter) fieldString(field *Field) string {
+	switch field.Type {
+	case StringType:
+		return field.String
+	case DurationType:
+		return time.Duration(field.Integer).String()
+	case UnknownType:
+		return ""
+	case ErrorType, AnyType:
+		return f.formatValue(field.Interface)
+	default:
+		return f.formatValue(field.Value())
 	}
-	_, err := writer.Write(buf.Bytes())
-	return err
 }
 
 func (f *TextFormatter) formatValue(o interface{}) string {
@@ -220,6 +313,8 @@ func formatValue(o interface{}, quote bool) string {
 	var ret string
 	if s, ok := o.(string); ok {
 		ret = s
+	} else if e, ok := o.(error); ok {
+		ret = e.Error()
 	} else {
 		ret = fmt.Sprint(o)
 	}
@@ -234,10 +329,114 @@ type JsonFormatter struct {
 }
 
 func (f *JsonFormatter) Format(writer io.Writer, keyValues KeyValues) error {
+	return formatToWriter(f, writer, keyValues)
+}
+
+func (f *JsonFormatter) appendFormat(buf []byte, keyValues KeyValues) ([]byte, error) {
+	if fs, ok := keyValues.(*Fields); ok {
+		return appendJsonFields(buf, *fs), nil
+	}
 	d, err := json.Marshal(keyValues.GetAll())
 	if err != nil {
-		return err
+		return buf, err
 	}
-	_, err = writer.Write(d)
-	return err
+	return append(buf, d...), nil
+}
+
+func appendJsonFields(buf []byte, fields Fields) []byte {
+	buf = append(buf, '{')
+	for i := range fields {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = appendJsonString(buf, fields[i].Key)
+		buf = append(buf, ':')
+		buf = appendJsonField(buf, &fields[i])
+	}
+	return append(buf, '}')
+}
+
+func appendJsonField(buf []byte, field *Field) []byte {
+	switch field.Type {
+	case StringType:
+		return appendJsonString(buf, field.String)
+	case Int64Type, DurationType:
+		return strconv.AppendInt(buf, field.Integer, 10)
+	case Uint64Type:
+		return strconv.AppendUint(buf, uint64(field.Integer), 10)
+	case Float64Type, Float32Type:
+		v, bitSize := field.float()
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return appendJsonString(buf, strconv.FormatFloat(v, 'g', -1, bitSize))
+		}
+		return strconv.AppendFloat(buf, v, 'g', -1, bitSize)
+	case BoolType:
+		return strconv.AppendBool(buf, field.Integer == 1)
+	case TimeType:
+		buf = append(buf, '"')
+		buf = field.time().AppendFormat(buf, time.RFC3339Nano)
+		return append(buf, '"')
+	case ErrorType:
+		return appendJsonString(buf, field.Interface.(error).Error())
+	case AnyType:
+		return appendJsonValue(buf, field.Interface)
+	default:
+		return append(buf, "null"...)
+	}
+}
+
+func appendJsonValue(buf []byte, o interface{}) []byte {
+	if _, ok := o.(json.Marshaler); !ok {
+		if e, ok := o.(error); ok {
+			return appendJsonString(buf, e.Error())
+		}
+	}
+	d, err := json.Marshal(o)
+	if err != nil {
+		return appendJsonString(buf, fmt.Sprint(o))
+	}
+	return append(buf, d...)
+}
+
+const hexDigits = "0123456789abcdef"
+
+func appendJsonString(buf []byte, s string) []byte {
+	buf = append(buf, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		b := s[i]
+		if b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' {
+				i++
+				continue
+			}
+			buf = append(buf, s[start:i]...)
+			switch b {
+			case '"', '\\':
+				buf = append(buf, '\\', b)
+			case '\n':
+				buf = append(buf, '\\', 'n')
+			case '\r':
+				buf = append(buf, '\\', 'r')
+			case '\t':
+				buf = append(buf, '\\', 't')
+			default:
+				buf = append(buf, '\\', 'u', '0', '0', hexDigits[b>>4], hexDigits[b&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, "\\ufffd"...)
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	buf = append(buf, s[start:]...)
+	return append(buf, '"')
 }
@@ -2,4 +2,7 @@ module github.com/xfali/xlog
 
 go 1.14
 
-require github.com/go-logr/logr v0.2.0
+require (
+	github.com/go-logr/logr v0.2.0
+	gopkg.in/yaml.v2 v2.4.0
+)
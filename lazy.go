@@ -0,0 +1,125 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package xlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// 延迟求值的值，仅在日志输出（级别开启且未被过滤）时调用求值，每次输出都会重新求值
+type LazyValue func() interface{}
+
+// 延迟求值的字符串，实现了fmt.Stringer，可用于输出代价较大的信息
+type LazyStringer func() string
+
+// 创建延迟求值的值，可作为日志参数（如Debugf的args）或附加信息（如WithFields的value）：
+// logger.Debugf("state: %v", xlog.Lazy(func() interface{} { return dump() }))
+func Lazy(f func() interface{}) LazyValue {
+	return f
+}
+
+// 创建延迟求值的字符串
+func LazyString(f func() string) LazyStringer {
+	return f
+}
+
+// 延迟求值的强类型日志附加信息
+func LazyField(key string, f func() interface{}) Field {
+	return Field{Key: key, Type: AnyType, Interface: LazyValue(f)}
+}
+
+func (f LazyValue) String() string {
+	return fmt.Sprint(f())
+}
+
+// 使用求值结果格式化，支持所有的格式化动词及标记
+func (f LazyValue) Format(s fmt.State, verb rune) {
+	fmt.Fprintf(s, formatVerb(s, verb), f())
+}
+
+func (f LazyValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(f())
+}
+
+func (f LazyStringer) String() string {
+	return f()
+}
+
+func (f LazyStringer) MarshalJSON() ([]byte, error) {
+	return json.Marshal(f())
+}
+
+// 还原fmt.State对应的格式化字符串
+func formatVerb(s fmt.State, verb rune) string {
+	buf := []byte{'%'}
+	for _, c := range "+-# 0" {
+		if s.Flag(int(c)) {
+			buf = append(buf, byte(c))
+		}
+	}
+	if w, ok := s.Width(); ok {
+		buf = strconv.AppendInt(buf, int64(w), 10)
+	}
+	if p, ok := s.Precision(); ok {
+		buf = append(buf, '.')
+		buf = strconv.AppendInt(buf, int64(p), 10)
+	}
+	return string(append(buf, string(verb)...))
+}
+
+func resolveLazyField(f Field) (Field, bool) {
+	if f.Type != AnyType {
+		return f, false
+	}
+	switch v := f.Interface.(type) {
+	case LazyValue:
+		return Any(f.Key, v()), true
+	case LazyStringer:
+		return String(f.Key, v()), true
+	}
+	return f, false
+}
+
+// 对KeyValues中延迟求值的值求值，不修改参数，有值被求值时返回新的KeyValues
+func resolveLazy(keyValues KeyValues) KeyValues {
+	if keyValues == nil {
+		return nil
+	}
+	if fs, ok := keyValues.(*Fields); ok {
+		var ret Fields
+		for i, f := range *fs {
+			nf, changed := resolveLazyField(f)
+			if !changed {
+				continue
+			}
+			if ret == nil {
+				ret = make(Fields, len(*fs))
+				copy(ret, *fs)
+			}
+			ret[i] = nf
+		}
+		if ret == nil {
+			return keyValues
+		}
+		// 仅在有修改时分配，避免ret逃逸到堆
+		out := ret
+		return &out
+	}
+
+	changed := false
+	ret := make(Fields, 0, keyValues.Len())
+	for _, k := range keyValues.Keys() {
+		nf, ok := resolveLazyField(Any(k, keyValues.Get(k)))
+		changed = changed || ok
+		ret = append(ret, nf)
+	}
+	if !changed {
+		return keyValues
+	}
+	return &ret
+}
@@ -0,0 +1,257 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package xlog
+
+import (
+	"errors"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// 根日志名称，配置该名称的日志级别等同于SetSeverityLevel
+const RootLoggerName = "root"
+
+// 初始的级别及颜色映射（AutoColor时使用），未配置时比WARN严重的级别使用红色，其他级别不使用颜色，
+// 仅在包初始化时读取，之后请使用RegisterLevel修改
+var LevelColor = map[Level]string{
+	INFO: ForeCyan,
+	WARN: ForeYellow,
+}
+
+// 已注册级别的名称、颜色及默认Writer，修改时复制
+type levelRegistry struct {
+	// 按严重程度从高到低排序
+	order   []Level
+	names   map[Level]string
+	colors  map[Level]string
+	writers map[Level]io.Writer
+}
+
+var (
+	// 类型为*levelRegistry，作为包级变量初始化，保证在defaultLogging之前完成
+	levels       = newLevels()
+	registerLock sync.Mutex
+)
+
+func newLevels() *atomic.Value {
+	r := &levelRegistry{
+		names:   make(map[Level]string, len(LogTag)),
+		colors:  make(map[Level]string, len(LevelColor)),
+		writers: make(map[Level]io.Writer, len(DefaultWriters)),
+	}
+	for k, v := range LogTag {
+		r.names[k] = v
+	}
+	for k, v := range LevelColor {
+		r.colors[k] = v
+	}
+	for k, v := range DefaultWriters {
+		r.writers[k] = v
+	}
+	r.sort()
+	ret := &atomic.Value{}
+	ret.Store(r)
+	return ret
+}
+
+func loadLevels() *levelRegistry {
+	return levels.Load().(*levelRegistry)
+}
+
+func (r *levelRegistry) clone() *levelRegistry {
+	ret := &levelRegistry{
+		names:   make(map[Level]string, len(r.names)+1),
+		colors:  make(map[Level]string, len(r.colors)+1),
+		writers: make(map[Level]io.Writer, len(r.writers)+1),
+	}
+	for k, v := range r.names {
+		ret.names[k] = v
+	}
+	for k, v := range r.colors {
+		ret.colors[k] = v
+	}
+	for k, v := range r.writers {
+		ret.writers[k] = v
+	}
+	return ret
+}
+
+func (r *levelRegistry) sort() {
+	r.order = make([]Level, 0, len(r.names))
+	for k := range r.names {
+		r.order = append(r.order, k)
+	}
+	sort.Slice(r.order, func(i, j int) bool {
+		return r.order[i] < r.order[j]
+	})
+}
+
+// 注册自定义日志级别，也可用于修改已有级别的名称、颜色及默认Writer（线程安全）
+// Param： level级别数值，数值越小越严重，内置级别为0~6，如VERBOSE可注册为TRACE+1；小于FATAL的级别不会被SetSeverityLevel过滤；
+// name级别名称；color AutoColor时使用的颜色，为空时使用默认规则；
+// w该级别默认的Writer，为nil时使用更低严重级别的Writer，注意只对之后创建的Logging有效
+func RegisterLevel(level Level, name string, color string, w io.Writer) {
+	registerLock.Lock()
+	defer registerLock.Unlock()
+
+	r := loadLevels().clone()
+	r.names[level] = name
+	if color != "" {
+		r.colors[level] = color
+	}
+	if w != nil {
+		r.writers[level] = w
+	}
+	r.sort()
+	levels.Store(r)
+}
+
+// 删除注册的自定义日志级别，内置级别不能删除（线程安全）
+func UnregisterLevel(level Level) {
+	switch level {
+	case FATAL, PANIC, ERROR, WARN, INFO, DEBUG, TRACE:
+		return
+	}
+	registerLock.Lock()
+	defer registerLock.Unlock()
+
+	r := loadLevels().clone()
+	delete(r.names, level)
+	delete(r.colors, level)
+	delete(r.writers, level)
+	r.sort()
+	levels.Store(r)
+}
+
+// 获得所有已注册的级别，按严重程度从高到低排序
+func Levels() []Level {
+	levels := loadLevels().order
+	ret := make([]Level, len(levels))
+	copy(ret, levels)
+	return ret
+}
+
+// 获得比参数级别严重程度低的已注册级别，按严重程度从高到低排序
+func levelsFrom(level Level) []Level {
+	levels := loadLevels().order
+	i := sort.Search(len(levels), func(i int) bool {
+		return levels[i] > level
+	})
+	return levels[i:]
+}
+
+func (l Level) String() string {
+	if s, ok := loadLevels().names[l]; ok {
+		return s
+	}
+	return "Level(" + strconv.Itoa(int(l)) + ")"
+}
+
+// 实现flag.Value，参数为级别名称或数字，参见ParseLevel
+func (l *Level) Set(s string) error {
+	lv, err := ParseLevel(s)
+	if err != nil {
+		return err
+	}
+	*l = lv
+	return nil
+}
+
+// 实现encoding.TextMarshaler，输出级别名称
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// 实现encoding.TextUnmarshaler，参见ParseLevel
+func (l *Level) UnmarshalText(text []byte) error {
+	return l.Set(string(text))
+}
+
+// 根据名称解析日志级别，名称不区分大小写，也可以是级别对应的数字，只接受已注册的级别，参见RegisterLevel
+func ParseLevel(s string) (Level, error) {
+	s = strings.TrimSpace(s)
+	names := loadLevels().names
+	for k, v := range names {
+		if strings.EqualFold(v, s) {
+			return k, nil
+		}
+	}
+	i, err := strconv.Atoi(s)
+	if err == nil {
+		if _, ok := names[Level(i)]; ok {
+			return Level(i), nil
+		}
+	}
+	return 0, errors.New("Unknown level: " + s)
+}
+
+// 解析按日志名称配置的日志级别，格式为：name=LEVEL，多个配置以','或';'分隔，
+// 如："root=INFO,db=DEBUG,db.pool=WARN"
+func ParseSeverityLevels(spec string) (map[string]Level, error) {
+	ret := map[string]Level{}
+	items := strings.FieldsFunc(spec, func(r rune) bool {
+		return r == ',' || r == ';'
+	})
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		i := strings.Index(item, "=")
+		if i == -1 {
+			return nil, errors.New("Level config must be name=LEVEL: " + item)
+		}
+		lv, err := ParseLevel(item[i+1:])
+		if err != nil {
+			return nil, err
+		}
+		ret[strings.TrimSpace(item[:i])] = lv
+	}
+	return ret, nil
+}
+
+// 按日志名称配置的日志级别，创建后不可修改
+type levelTree map[string]Level
+
+// 按最长的名称前缀（以'.'分隔）查找日志级别
+func (t levelTree) find(name string) (Level, bool) {
+	for name != "" {
+		if lv, ok := t[name]; ok {
+			return lv, true
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i == -1 {
+			break
+		}
+		name = name[:i]
+	}
+	return 0, false
+}
+
+func isRootLoggerName(name string) bool {
+	return name == "" || name == RootLoggerName
+}
+
+// 获得KeyValues中的日志名称
+func loggerName(keyValues KeyValues) string {
+	if keyValues == nil {
+		return ""
+	}
+	if fs, ok := keyValues.(*Fields); ok {
+		if i := fs.index(KeyName); i != -1 && (*fs)[i].Type == StringType {
+			return (*fs)[i].String
+		}
+		return ""
+	}
+	if name, ok := keyValues.Get(KeyName).(string); ok {
+		return name
+	}
+	return ""
+}
@@ -0,0 +1,147 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package xlog
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// 修改日志级别请求体的大小限制
+const maxLevelPayloadSize = 64 << 10
+
+type levelHandler struct {
+	getLogging func() Logging
+}
+
+type levelPayload struct {
+	// 日志名称，为空时为根级别
+	Name string `json:"name,omitempty"`
+	// 日志级别名称，参见RegisterLevel
+	Level string `json:"level,omitempty"`
+	// 按日志名称配置的日志级别
+	Loggers map[string]string `json:"loggers,omitempty"`
+}
+
+type levelError struct {
+	Error string `json:"error"`
+}
+
+// 查看及修改Logging日志级别的http.Handler：
+// GET 返回当前的日志级别，如：{"level":"INFO","loggers":{"db":"DEBUG"}}，
+// 带参数name时返回该名称生效的日志级别，如：GET ?name=db.pool 返回 {"name":"db.pool","level":"DEBUG"}；
+// PUT/POST 修改日志级别，支持JSON及表单，如：{"level":"DEBUG"}、{"name":"db","level":"DEBUG"}、
+// {"loggers":{"db":"DEBUG","db.pool":"WARN"}}，只接受已注册的级别，返回修改后的日志级别
+func NewLevelHandler(logging Logging) http.Handler {
+	return &levelHandler{
+		getLogging: func() Logging {
+			return logging
+		},
+	}
+}
+
+// 查看及修改默认Logging日志级别的http.Handler，参见NewLevelHandler
+func DefaultLevelHandler() http.Handler {
+	return &levelHandler{
+		getLogging: DefaultLogging,
+	}
+}
+
+func (h *levelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logging := h.getLogging()
+	switch r.Method {
+	case http.MethodGet:
+		h.writeLevels(w, logging, r.URL.Query().Get("name"))
+	case http.MethodPut, http.MethodPost:
+		r.Body = http.MaxBytesReader(w, r.Body, maxLevelPayloadSize)
+		payload, err := decodeLevelPayload(r)
+		if err == nil {
+			err = applyLevelPayload(logging, payload)
+		}
+		if err != nil {
+			writeLevelJson(w, http.StatusBadRequest, levelError{Error: err.Error()})
+			return
+		}
+		h.writeLevels(w, logging, payload.Name)
+	default:
+		w.Header().Set("Allow", "GET, PUT, POST")
+		writeLevelJson(w, http.StatusMethodNotAllowed, levelError{Error: "Only GET, PUT and POST are supported"})
+	}
+}
+
+func (h *levelHandler) writeLevels(w http.ResponseWriter, logging Logging, name string) {
+	levels := logging.GetSeverityLevels()
+	root := levels[RootLoggerName]
+	delete(levels, RootLoggerName)
+	if !isRootLoggerName(name) {
+		lv, ok := levelTree(levels).find(name)
+		if !ok {
+			lv = root
+		}
+		writeLevelJson(w, http.StatusOK, levelPayload{Name: name, Level: lv.String()})
+		return
+	}
+
+	ret := levelPayload{
+		Level: root.String(),
+	}
+	if len(levels) > 0 {
+		ret.Loggers = make(map[string]string, len(levels))
+		for k, v := range levels {
+			ret.Loggers[k] = v.String()
+		}
+	}
+	writeLevelJson(w, http.StatusOK, ret)
+}
+
+func decodeLevelPayload(r *http.Request) (levelPayload, error) {
+	var ret levelPayload
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		if err := r.ParseForm(); err != nil {
+			return ret, err
+		}
+		ret.Name = r.Form.Get("name")
+		ret.Level = r.Form.Get("level")
+	} else {
+		if err := json.NewDecoder(r.Body).Decode(&ret); err != nil {
+			return ret, errors.New("Request body must be json: " + err.Error())
+		}
+	}
+	if ret.Level == "" && len(ret.Loggers) == 0 {
+		return ret, errors.New("Level must be not empty ")
+	}
+	return ret, nil
+}
+
+func applyLevelPayload(logging Logging, payload levelPayload) error {
+	levels := make(map[string]Level, len(payload.Loggers))
+	for k, v := range payload.Loggers {
+		lv, err := ParseLevel(v)
+		if err != nil {
+			return err
+		}
+		levels[k] = lv
+	}
+	if payload.Level != "" {
+		lv, err := ParseLevel(payload.Level)
+		if err != nil {
+			return err
+		}
+		logging.SetSeverityLevelByName(payload.Name, lv)
+	}
+	for k, v := range levels {
+		logging.SetSeverityLevelByName(k, v)
+	}
+	return nil
+}
+
+func writeLevelJson(w http.ResponseWriter, status int, o interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(o)
+}
@@ -0,0 +1,219 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package xlog
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// 可将缓存的数据同步写入的Writer，writer包中的异步Writer均实现了该接口
+type Syncer interface {
+	Sync() error
+}
+
+// 发生FATAL日志时需要处理的Writer，如writer.Ring将保存的日志输出到文件，
+// 需注册到xlog（参见RegisterWriter），在退出前调用。PANIC日志可能被recover，不会调用
+type CrashHandler interface {
+	OnCrash() error
+}
+
+// FATAL日志退出前等待同步已注册Writer的最长时间
+var FatalSyncTimeout = 3 * time.Second
+
+// 已注册的Writer，refs为通过SetOutput及SetOutputBySeverity自动注册的引用数，
+// explicit为是否通过RegisterWriter注册
+type registeredWriter struct {
+	w        io.Writer
+	refs     int
+	explicit bool
+}
+
+var (
+	writersLock sync.Mutex
+	writers     []*registeredWriter
+)
+
+// 注册Writer，Sync时同步所有实现了Syncer的Writer，Shutdown时同步并关闭所有实现了io.Closer的Writer，
+// 发生FATAL日志时调用所有实现了CrashHandler的Writer。
+// 重复注册、注册os.Stdout及os.Stderr无效。
+// 内置Logging的SetOutput及SetOutputBySeverity会自动注册实现了Syncer、io.Closer或CrashHandler的Writer，
+// 被替换后不再被任何Logging使用时自动取消注册（通过RegisterWriter注册的Writer除外）
+func RegisterWriter(w io.Writer) {
+	if !registrable(w) {
+		return
+	}
+	writersLock.Lock()
+	defer writersLock.Unlock()
+
+	findWriter(w, true).explicit = true
+}
+
+// 取消注册Writer，返回Writer是否已注册
+func UnregisterWriter(w io.Writer) bool {
+	writersLock.Lock()
+	defer writersLock.Unlock()
+
+	for i, v := range writers {
+		if v.w == w {
+			writers = append(writers[:i], writers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func registeredWriters() []io.Writer {
+	writersLock.Lock()
+	defer writersLock.Unlock()
+
+	ret := make([]io.Writer, len(writers))
+	for i, v := range writers {
+		ret[i] = v.w
+	}
+	return ret
+}
+
+func registrable(w io.Writer) bool {
+	return w != nil && w != os.Stdout && w != os.Stderr
+}
+
+// 查找已注册的Writer，add为true时未注册则注册，需持有writersLock
+func findWriter(w io.Writer, add bool) *registeredWriter {
+	for _, v := range writers {
+		if v.w == w {
+			return v
+		}
+	}
+	if !add {
+		return nil
+	}
+	ret := &registeredWriter{w: w}
+	writers = append(writers, ret)
+	return ret
+}
+
+// 自动注册需要同步或关闭的Writer，增加引用数
+func registerOutput(w io.Writer) {
+	switch w.(type) {
+	case Syncer, io.Closer, CrashHandler:
+		if !registrable(w) {
+			return
+		}
+		writersLock.Lock()
+		defer writersLock.Unlock()
+
+		findWriter(w, true).refs++
+	}
+}
+
+// 减少自动注册的引用数，不再被使用时取消注册
+func releaseOutput(w io.Writer) {
+	if !registrable(w) {
+		return
+	}
+	writersLock.Lock()
+	defer writersLock.Unlock()
+
+	v := findWriter(w, false)
+	if v == nil || v.refs == 0 {
+		return
+	}
+	v.refs--
+	if v.refs == 0 && !v.explicit {
+		for i, r := range writers {
+			if r == v {
+				writers = append(writers[:i], writers[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// 调用已注册的CrashHandler，忽略返回的错误
+func handleCrash() {
+	for _, w := range registeredWriters() {
+		if h, ok := w.(CrashHandler); ok {
+			h.OnCrash()
+		}
+	}
+}
+
+// 同步Writer（如果实现了Syncer），忽略不支持同步的文件（如管道、终端）返回的错误
+func syncWriter(w io.Writer) error {
+	if s, ok := w.(Syncer); ok {
+		err := s.Sync()
+		if errors.Is(err, syscall.EINVAL) || errors.Is(err, syscall.ENOTSUP) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// 同步所有已注册的Writer，返回第一个错误
+func Sync() error {
+	var ret error
+	for _, w := range registeredWriters() {
+		if err := syncWriter(w); err != nil && ret == nil {
+			ret = err
+		}
+	}
+	return ret
+}
+
+// 同步并关闭所有已注册的Writer（包括Logging当前使用的自动注册的Writer），关闭后取消注册。
+// ctx超时返回ctx.Err()，此时同步关闭仍在后台继续
+func Shutdown(ctx context.Context) error {
+	writersLock.Lock()
+	ws := writers
+	writers = nil
+	writersLock.Unlock()
+
+	done := make(chan error, 1)
+	go func() {
+		var ret error
+		for _, v := range ws {
+			if err := syncWriter(v.w); err != nil && ret == nil {
+				ret = err
+			}
+			if c, ok := v.w.(io.Closer); ok {
+				if err := c.Close(); err != nil && ret == nil {
+					ret = err
+				}
+			}
+		}
+		done <- ret
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// 在timeout时间内同步所有已注册的Writer
+func syncWithTimeout(timeout time.Duration) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- Sync()
+	}()
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-t.C:
+		return context.DeadlineExceeded
+	}
+}
@@ -6,6 +6,7 @@
 package xlog
 
 import (
+	"context"
 	"github.com/xfali/xlog/value"
 )
 
@@ -106,6 +107,96 @@ func Fatalf(fmt string, args ...interface{}) {
 	DefaultLogging().Logf(FATAL, LogDepth.Load().(int), getLogField(), fmt, args...)
 }
 
+// 使用默认的Logging，输出Debug级别的日志，附加从ctx中提取的信息
+func DebugCtx(ctx context.Context, args ...interface{}) {
+	DefaultLogging().Log(DEBUG, LogDepth.Load().(int), getCtxLogField(ctx), args...)
+}
+
+// 使用默认的Logging，输出Debug级别的日志，附加从ctx中提取的信息
+func DebuglnCtx(ctx context.Context, args ...interface{}) {
+	DefaultLogging().Logln(DEBUG, LogDepth.Load().(int), getCtxLogField(ctx), args...)
+}
+
+// 使用默认的Logging，输出Debug级别的日志，附加从ctx中提取的信息
+func DebugfCtx(ctx context.Context, fmt string, args ...interface{}) {
+	DefaultLogging().Logf(DEBUG, LogDepth.Load().(int), getCtxLogField(ctx), fmt, args...)
+}
+
+// 使用默认的Logging，输出Info级别的日志，附加从ctx中提取的信息
+func InfoCtx(ctx context.Context, args ...interface{}) {
+	DefaultLogging().Log(INFO, LogDepth.Load().(int), getCtxLogField(ctx), args...)
+}
+
+// 使用默认的Logging，输出Info级别的日志，附加从ctx中提取的信息
+func InfolnCtx(ctx context.Context, args ...interface{}) {
+	DefaultLogging().Logln(INFO, LogDepth.Load().(int), getCtxLogField(ctx), args...)
+}
+
+// 使用默认的Logging，输出Info级别的日志，附加从ctx中提取的信息
+func InfofCtx(ctx context.Context, fmt string, args ...interface{}) {
+	DefaultLogging().Logf(INFO, LogDepth.Load().(int), getCtxLogField(ctx), fmt, args...)
+}
+
+// 使用默认的Logging，输出Warn级别的日志，附加从ctx中提取的信息
+func WarnCtx(ctx context.Context, args ...interface{}) {
+	DefaultLogging().Log(WARN, LogDepth.Load().(int), getCtxLogField(ctx), args...)
+}
+
+// 使用默认的Logging，输出Warn级别的日志，附加从ctx中提取的信息
+func WarnlnCtx(ctx context.Context, args ...interface{}) {
+	DefaultLogging().Logln(WARN, LogDepth.Load().(int), getCtxLogField(ctx), args...)
+}
+
+// 使用默认的Logging，输出Warn级别的日志，附加从ctx中提取的信息
+func WarnfCtx(ctx context.Context, fmt string, args ...interface{}) {
+	DefaultLogging().Logf(WARN, LogDepth.Load().(int), getCtxLogField(ctx), fmt, args...)
+}
+
+// 使用默认的Logging，输出Error级别的日志，附加从ctx中提取的信息
+func ErrorCtx(ctx context.Context, args ...interface{}) {
+	DefaultLogging().Log(ERROR, LogDepth.Load().(int), getCtxLogField(ctx), args...)
+}
+
+// 使用默认的Logging，输出Error级别的日志，附加从ctx中提取的信息
+func ErrorlnCtx(ctx context.Context, args ...interface{}) {
+	DefaultLogging().Logln(ERROR, LogDepth.Load().(int), getCtxLogField(ctx), args...)
+}
+
+// 使用默认的Logging，输出Error级别的日志，附加从ctx中提取的信息
+func ErrorfCtx(ctx context.Context, fmt string, args ...interface{}) {
+	DefaultLogging().Logf(ERROR, LogDepth.Load().(int), getCtxLogField(ctx), fmt, args...)
+}
+
+// 使用默认的Logging，输出Panic级别的日志，注意会触发panic，附加从ctx中提取的信息
+func PanicCtx(ctx context.Context, args ...interface{}) {
+	DefaultLogging().Log(PANIC, LogDepth.Load().(int), getCtxLogField(ctx), args...)
+}
+
+// 使用默认的Logging，输出Panic级别的日志，注意会触发panic，附加从ctx中提取的信息
+func PaniclnCtx(ctx context.Context, args ...interface{}) {
+	DefaultLogging().Logln(PANIC, LogDepth.Load().(int), getCtxLogField(ctx), args...)
+}
+
+// 使用默认的Logging，输出Panic级别的日志，注意会触发panic，附加从ctx中提取的信息
+func PanicfCtx(ctx context.Context, fmt string, args ...interface{}) {
+	DefaultLogging().Logf(PANIC, LogDepth.Load().(int), getCtxLogField(ctx), fmt, args...)
+}
+
+// 使用默认的Logging，输出Fatal级别的日志，注意会触发程序退出，附加从ctx中提取的信息
+func FatalCtx(ctx context.Context, args ...interface{}) {
+	DefaultLogging().Log(FATAL, LogDepth.Load().(int), getCtxLogField(ctx), args...)
+}
+
+// 使用默认的Logging，输出Fatal级别的日志，注意会触发程序退出，附加从ctx中提取的信息
+func FatallnCtx(ctx context.Context, args ...interface{}) {
+	DefaultLogging().Logln(FATAL, LogDepth.Load().(int), getCtxLogField(ctx), args...)
+}
+
+// 使用默认的Logging，输出Fatal级别的日志，注意会触发程序退出，附加从ctx中提取的信息
+func FatalfCtx(ctx context.Context, fmt string, args ...interface{}) {
+	DefaultLogging().Logf(FATAL, LogDepth.Load().(int), getCtxLogField(ctx), fmt, args...)
+}
+
 func getLogField() KeyValues {
 	ret := LogFields.Load()
 	if ret == nil {
@@ -114,6 +205,19 @@ func getLogField() KeyValues {
 	return ret.(KeyValues)
 }
 
+func getCtxLogField(ctx context.Context) KeyValues {
+	ctxKvs := ExtractContext(ctx)
+	if ctxKvs == nil {
+		return getLogField()
+	}
+	fields := getLogField()
+	if fields == nil {
+		return ctxKvs
+	}
+	ret, _ := MergeKeyValues(fields.Clone(), ctxKvs)
+	return ret
+}
+
 // 配置默认的调用深度
 func WithDepth(depth int) {
 	LogDepth.Store(depth)
@@ -5,11 +5,14 @@
 
 package xlog
 
+import "context"
+
 type LogDebug interface {
 	DebugEnabled() bool
 	Debug(args ...interface{})
 	Debugln(args ...interface{})
 	Debugf(fmt string, args ...interface{})
+	DebugFields(msg string, fields ...Field)
 }
 
 type LogInfo interface {
@@ -17,6 +20,7 @@ type LogInfo interface {
 	Info(args ...interface{})
 	Infoln(args ...interface{})
 	Infof(fmt string, args ...interface{})
+	InfoFields(msg string, fields ...Field)
 }
 
 type LogWarn interface {
@@ -24,6 +28,7 @@ type LogWarn interface {
 	Warn(args ...interface{})
 	Warnln(args ...interface{})
 	Warnf(fmt string, args ...interface{})
+	WarnFields(msg string, fields ...Field)
 }
 
 type LogError interface {
@@ -31,6 +36,7 @@ type LogError interface {
 	Error(args ...interface{})
 	Errorln(args ...interface{})
 	Errorf(fmt string, args ...interface{})
+	ErrorFields(msg string, fields ...Field)
 }
 
 type LogPanic interface {
@@ -38,6 +44,7 @@ type LogPanic interface {
 	Panic(args ...interface{})
 	Panicln(args ...interface{})
 	Panicf(fmt string, args ...interface{})
+	PanicFields(msg string, fields ...Field)
 }
 
 type LogFatal interface {
@@ -45,6 +52,7 @@ type LogFatal interface {
 	Fatal(args ...interface{})
 	Fatalln(args ...interface{})
 	Fatalf(fmt string, args ...interface{})
+	FatalFields(msg string, fields ...Field)
 }
 
 // Logger是xlog的日志封装工具，实现了常用的日志方法
@@ -62,12 +70,33 @@ type Logger interface {
 	// Fatal级别日志接口，注意会触发程序退出
 	LogFatal
 
+	// 判断是否输出参数级别的日志
+	IsEnabled(severityLevel Level) bool
+
+	// 输出参数级别的日志，可用于TRACE及通过RegisterLevel注册的自定义级别
+	Log(level Level, args ...interface{})
+
+	// 输出参数级别的日志，末尾增加换行
+	Logln(level Level, args ...interface{})
+
+	// 输出参数级别的format日志
+	Logf(level Level, fmt string, args ...interface{})
+
 	// 附加日志名称，注意会附加父Logger的名称，格式为：父Logger名称 + '.' + name
 	WithName(name string) Logger
 
 	// 附加日志信息，注意会附加父Logger的附加信息，如果相同则会覆盖
 	WithFields(keyAndValues ...interface{}) Logger
 
+	// 附加强类型的日志信息，注意会附加父Logger的附加信息，如果相同则会覆盖
+	With(fields ...Field) Logger
+
 	// 配置日志的调用深度，注意会在父Logger的基础上调整深度
 	WithDepth(depth int) Logger
+
+	// 附加通过已注册的ContextExtractor从ctx中提取的日志信息，注意会附加父Logger的附加信息，如果相同则会覆盖
+	WithContext(ctx context.Context) Logger
+
+	// 捕获panic并记录panic值及调用栈，保留Logger的名称及附加信息，需直接defer调用：defer logger.Recover()
+	Recover(opts ...RecoverOpt)
 }
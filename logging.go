@@ -6,12 +6,14 @@
 package xlog
 
 import (
-	"bytes"
 	"fmt"
 	"github.com/xfali/xlog/value"
 	"io"
+	"io/ioutil"
 	"os"
+	"reflect"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -19,7 +21,8 @@ import (
 	"time"
 )
 
-type Level = int32
+// 日志级别，数值越小越严重，可以通过RegisterLevel注册自定义级别
+type Level int32
 
 const (
 	FATAL Level = 0
@@ -28,6 +31,7 @@ const (
 	WARN  Level = 3
 	INFO  Level = 4
 	DEBUG Level = 5
+	TRACE Level = 6
 )
 
 const (
@@ -69,6 +73,12 @@ const (
 	KeyContent = "LogContent"
 	// 日志名称Key
 	KeyName = "LogName"
+	// 错误信息Key
+	KeyError = "LogError"
+	// 错误链Key，参见SetExtractErrors
+	KeyErrorCauses = KeyError + errorCausesSuffix
+	// 调用栈，参见SetStackTraceLevel
+	KeyStack = "LogStack"
 )
 
 var (
@@ -102,8 +112,9 @@ var (
 	ResetColor = "\033[0m"
 )
 
-// 级别及名称映射
+// 初始的级别及名称映射，仅在包初始化时读取，之后请使用RegisterLevel修改及Level.String获得名称
 var LogTag = map[Level]string{
+	TRACE: "TRACE",
 	DEBUG: "DEBUG",
 	INFO:  "INFO",
 	WARN:  "WARN",
@@ -118,7 +129,9 @@ var (
 	DefaultPrintFileFlag = CallerShortFile
 	DefaultFatalNoTrace  = false
 	DefaultLevel         = INFO
-	DefaultWriters       = map[Level]io.Writer{
+	// 初始的级别及默认Writer映射，仅在包初始化时读取，之后请使用RegisterLevel修改
+	DefaultWriters = map[Level]io.Writer{
+		TRACE: os.Stdout,
 		DEBUG: os.Stdout,
 		INFO:  os.Stdout,
 		WARN:  os.Stdout,
@@ -147,21 +160,75 @@ type Logging interface {
 	// 设置日志格式化工具（线程安全）
 	SetFormatter(f Formatter)
 
+	// 获得日志格式化工具，未设置时返回nil（线程安全）
+	GetFormatter() Formatter
+
+	// 设置对应日志级别的格式化工具，f为nil时删除该级别的配置。
+	// 级别未配置时使用下一个配置了的较轻的级别（与选择Writer的规则相同），都未配置时使用SetFormatter设置的格式化工具，
+	// 注意SetFormatter会清除按级别配置的格式化工具（线程安全）
+	SetFormatterBySeverity(severityLevel Level, f Formatter)
+
+	// 获得对应日志级别配置的格式化工具，未配置时返回nil（线程安全）
+	GetFormatterBySeverity(severityLevel Level) Formatter
+
 	// 设置日志严重级别，低于该级别的将不被输出（线程安全）
 	SetSeverityLevel(severityLevel Level)
 
+	// 获得日志严重级别（线程安全）
+	GetSeverityLevel() Level
+
+	// 设置调用者的输出标志，参见CallerShortFile、CallerLongFile等（线程安全）
+	SetCallerFlag(flag int)
+
+	// 设置颜色的标志，有AutoColor、DisableColor、ForceColor（线程安全）
+	SetColorFlag(flag int)
+
 	// 判断参数级别是否会输出（线程安全）
 	IsEnabled(severityLevel Level) bool
 
-	// 设置输出的Writer，注意该方法会将所有级别都配置为参数writer（线程安全）
+	// 设置日志名称对应的日志级别，子名称（以'.'分隔）未配置时使用最长匹配的父名称的级别，
+	// 名称为空或RootLoggerName时等同于SetSeverityLevel（线程安全）
+	SetSeverityLevelByName(name string, severityLevel Level)
+
+	// 重置所有日志名称对应的日志级别，RootLoggerName对应的级别等同于SetSeverityLevel（线程安全）
+	SetSeverityLevels(levels map[string]Level)
+
+	// 获得所有日志名称对应的日志级别，包含RootLoggerName对应的级别（线程安全）
+	GetSeverityLevels() map[string]Level
+
+	// 判断参数名称的日志是否输出参数级别，名称为空时等同于IsEnabled（线程安全）
+	IsEnabledByName(name string, severityLevel Level) bool
+
+	// 设置输出的Writer，注意该方法会将所有级别都配置为参数writer，
+	// 实现了Syncer或io.Closer的writer会注册到xlog，参见RegisterWriter（线程安全）
 	SetOutput(w io.Writer)
 
-	// 设置对应日志级别的Writer（线程安全）
+	// 设置对应日志级别的Writer，实现了Syncer或io.Closer的writer会注册到xlog，参见RegisterWriter（线程安全）
 	SetOutputBySeverity(severityLevel Level, w io.Writer)
 
 	// 获得对应日志级别的Writer（线程安全）
 	GetOutputBySeverity(severityLevel Level) io.Writer
 
+	// 添加Appender，日志在输出到级别对应的Writer后再交给所有Appender输出，
+	// 仅使用Appender输出时可将级别对应的Writer配置为ioutil.Discard（线程安全）
+	AddAppender(appender Appender)
+
+	// 删除添加的Appender，appender需为可比较的类型（如指针），未添加时不处理（线程安全）
+	RemoveAppender(appender Appender)
+
+	// 获得所有Appender（线程安全）
+	GetAppenders() []Appender
+
+	// 添加过滤器，过滤器在格式化之前按添加顺序执行，结果为FilterDeny时丢弃日志，
+	// 为FilterAccept时不再执行后续过滤器（线程安全）
+	AddFilter(filter Filter)
+
+	// 获得写入及格式化失败的统计（线程安全）
+	GetErrorStats() ErrorStats
+
+	// 获得当前配置的快照（线程安全）
+	Config() LoggingConfig
+
 	// 获得一个clone的对象（线程安全）
 	Clone() Logging
 }
@@ -170,20 +237,48 @@ type ExitFunc func(code int)
 type PanicFunc func(interface{})
 
 type logging struct {
+	// 失败统计，使用atomic操作，放在首位保证64位对齐
+	errors errorCounter
+
 	timeFormatter   func(t time.Time) string
 	callerFormatter func(file string, line int, funcName string) string
 	exitFunc        ExitFunc
 	panicFunc       PanicFunc
-	formatter       atomic.Value
-	colorFlag       int
-	fileFlag        int
-	fatalNoTrace    bool
+	errorHandler    ErrorHandler
+	redaction       *Redaction
+	// 类型为formatterHolder，修改时复制
+	formatter     atomic.Value
+	formatterLock sync.Mutex
+	// 使用atomic操作，参见SetColorFlag、SetCallerFlag
+	colorFlag int32
+	fileFlag  int32
+	// 不为nil时按包路径及函数名称跳过调用者，忽略调用深度
+	callerSkip   *callerSkipper
+	callers      *callerCache
+	fatalNoTrace bool
+	// 每条日志附加的静态信息及协程ID的Key，参见SetStaticFields、SetGoroutineID
+	staticFields Fields
+	goroutineKey string
+	// 是否在日志级别达到stackLevel时附加调用栈
+	withStack     bool
+	stackLevel    Level
+	extractErrors bool
 
 	level Level
+	// 按日志名称配置的日志级别，类型为levelTree
+	levels    atomic.Value
+	levelLock sync.Mutex
 
 	writers sync.Map
+	// 修改writers时持有，保证自动注册的引用数正确
+	outputLock sync.Mutex
+	// 类型为[]Appender，修改时复制
+	appenders    atomic.Value
+	appenderLock sync.Mutex
+	// 类型为[]Filter，修改时复制
+	filters    atomic.Value
+	filterLock sync.Mutex
 
-	bufPool sync.Pool
 }
 
 var defaultLogging value.Value = value.NewSimpleValue(Logging(NewLogging()))
@@ -195,19 +290,16 @@ func NewLogging(opts ...LoggingOpt) Logging {
 		exitFunc:        defaultExit,
 		panicFunc:       defaultPanic,
 		//formatter:     nil,
-		colorFlag:    DefaultColorFlag,
-		fileFlag:     DefaultPrintFileFlag,
+		colorFlag:    int32(DefaultColorFlag),
+		fileFlag:     int32(DefaultPrintFileFlag),
+		callers:      &callerCache{},
 		fatalNoTrace: DefaultFatalNoTrace,
 		level:        DefaultLevel,
 
 		//writers: map[Level]io.Writer{},
-
-		bufPool: sync.Pool{New: func() interface{} {
-			return bytes.NewBuffer(nil)
-		}},
 	}
 
-	for k, v := range DefaultWriters {
+	for k, v := range loadLevels().writers {
 		ret.writers.Store(k, v)
 	}
 
@@ -218,31 +310,57 @@ func NewLogging(opts ...LoggingOpt) Logging {
 }
 
 func (l *logging) getCaller(depth int) string {
-	if l.fileFlag != CallerNone {
-		pc, file, line, ok := runtime.Caller(3 + depth)
+	fileFlag := l.getCallerFlag()
+	if fileFlag != CallerNone {
+		if l.callerSkip != nil {
+			frame, ok := l.callerSkip.caller()
+			return l.formatCaller(fileFlag, frame.PC, frame.Function, frame.File, frame.Line, ok)
+		}
+		var pcs [1]uintptr
+		if runtime.Callers(4+depth, pcs[:]) == 0 {
+			return l.formatCaller(fileFlag, 0, "", "", 0, false)
+		}
+		pc := pcs[0]
+		if caller, ok := l.callers.get(pc, fileFlag); ok {
+			return caller
+		}
+		frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+		caller := l.formatCaller(fileFlag, frame.PC, frame.Function, frame.File, frame.Line, true)
+		l.callers.put(pc, fileFlag, caller)
+		return caller
+	}
+	return ""
+}
+
+// funcName为空时使用pc获得函数名称，未配置输出函数名称时忽略funcName
+func (l *logging) formatCaller(fileFlag int, pc uintptr, funcName string, file string, line int, ok bool) string {
+	if fileFlag != CallerNone {
 		if !ok {
 			return "???"
 		}
 
-		if (l.fileFlag & CallerShortFile) != 0 {
+		if (fileFlag & CallerShortFile) != 0 {
 			file = shortFile(file)
 		}
 
-		if (l.fileFlag & CallerFileMask) == 0 {
+		if (fileFlag & CallerFileMask) == 0 {
 			file = ""
 			line = -1
 		}
-		var funcName string
-		if (l.fileFlag & CallerFuncMask) != 0 {
-			funcName = runtime.FuncForPC(pc).Name()
-			if (l.fileFlag & CallerShortFunc) != 0 {
+		if (fileFlag & CallerFuncMask) != 0 {
+			if funcName == "" {
+				funcName = runtime.FuncForPC(pc).Name()
+			}
+			if (fileFlag & CallerShortFunc) != 0 {
 				idx := strings.LastIndex(funcName, ".")
 				if idx != -1 && idx < (len(funcName)-1) {
 					funcName = funcName[idx+1:]
 				}
-			} else if (l.fileFlag & CallerSimpleFunc) != 0 {
+			} else if (fileFlag & CallerSimpleFunc) != 0 {
 				funcName = simpleFuncName(funcName)
 			}
+		} else {
+			funcName = ""
 		}
 		return l.callerFormatter(file, line, funcName)
 	}
@@ -250,152 +368,331 @@ func (l *logging) getCaller(depth int) string {
 }
 
 func (l *logging) format(writer io.Writer, level Level, depth int, keyValues KeyValues, log string) {
-	caller := l.getCaller(depth)
-
 	var (
-		lvColor    string
-		resetColor string
+		caller   string
+		t        time.Time
+		recorded bool
 	)
-	if l.colorFlag == AutoColor {
-		lvColor = selectLevelColor(level)
-		resetColor = ResetColor
+	// 回放缓存的日志时使用记录时的时间及调用者
+	if r, ok := keyValues.(*recordedKeyValues); ok {
+		caller = l.formatCaller(l.getCallerFlag(), r.pc, r.function, r.file, r.line, r.ok)
+		t = r.time
+		keyValues = r.KeyValues
+		recorded = true
+	} else {
+		caller = l.getCaller(depth)
+		t = time.Now()
 	}
 
-	//if log == "" || log[len(log)-1] != '\n' {
-	//	log += "\n"
-	//}
+	keyValues = resolveLazy(l.processFields(keyValues))
+	if l.redaction != nil {
+		keyValues = l.redaction.Redact(keyValues)
+		log = l.redaction.RedactString(log)
+	}
+	entry := Entry{
+		Time:      t,
+		Level:     level,
+		Caller:    caller,
+		KeyValues: keyValues,
+		msg:       log,
+	}
+	if l.withStack && !recorded && level <= l.stackLevel && (keyValues == nil || keyValues.Get(KeyStack) == nil) {
+		if l.callerSkip != nil {
+			entry.Stack = l.callerSkip.trim(CaptureStackTrace(0))
+		} else {
+			entry.Stack = CaptureStackTrace(2 + depth)
+		}
+	}
+	// 输出为ioutil.Discard时不格式化，用于仅使用Appender输出的场景
+	if writer != ioutil.Discard {
+		op, err := formatEntry(writer, l.selectFormatter(level), l.getColorFlag(), l.timeFormatter, &entry)
+		if err != nil {
+			l.handleError(op, level, err)
+		}
+	}
+
+	appenders := l.loadAppenders()
+	if len(appenders) > 0 {
+		// 日志内容可能引用池化的缓存，复制后交给Appender
+		e := getEntry()
+		*e = entry
+		e.msg = cloneString(log)
+		e.Name = loggerName(keyValues)
+		for _, a := range appenders {
+			if !a.IsEnabled(level) {
+				continue
+			}
+			if err := a.Append(e); err != nil {
+				if le, ok := err.(*LoggingError); ok {
+					l.handleError(le.Op, level, le.Err)
+				} else {
+					l.handleError(ErrorOpWrite, level, err)
+				}
+			}
+		}
+		putEntry(e)
+	}
+}
 
-	formatter := l.formatter.Load()
+// 使用Formatter格式化并输出日志条目，Formatter为nil时使用内置格式，返回失败的操作（ErrorOpWrite、ErrorOpFormat）及错误。
+// 内置格式及内置Formatter格式化到池化的缓存，不产生中间字符串
+func formatEntry(writer io.Writer, formatter Formatter, colorFlag int, timeFormatter func(t time.Time) string, entry *Entry) (string, error) {
+	log := entry.Message()
 	if formatter != nil {
-		innerKvs := NewKeyValues()
-		innerKvs.Add(KeyTimestamp, time.Now(), KeySeverityLevel, LogTag[level], KeyCaller, caller)
-		MergeKeyValues(innerKvs, keyValues)
+		innerKvs := getFields()
+		defer putFields(innerKvs)
+		innerKvs.AddFields(Time(KeyTimestamp, entry.Time), String(KeySeverityLevel, entry.Level.String()), String(KeyCaller, entry.Caller))
+		MergeKeyValues(innerKvs, entry.KeyValues)
 		if log == "\n" {
 			log = ""
 		}
-		innerKvs.Add(KeyContent, log)
-		formatter.(Formatter).Format(writer, innerKvs)
+		if bf, ok := formatter.(bufferFormatter); ok {
+			innerKvs.AddFields(String(KeyContent, log))
+			if len(entry.Stack) > 0 {
+				innerKvs.AddFields(Any(KeyStack, entry.Stack))
+			}
+			buf := getBuffer()
+			defer putBuffer(buf)
+			var err error
+			buf.b, err = bf.appendFormat(buf.b, innerKvs)
+			if err != nil {
+				return ErrorOpFormat, err
+			}
+			if len(buf.b) == 0 {
+				return "", nil
+			}
+			if _, err = writer.Write(buf.b); err != nil {
+				return ErrorOpWrite, err
+			}
+			return "", nil
+		}
+
+		// 自定义的Formatter可能保存日志内容，使用复制的字符串
+		innerKvs.AddFields(String(KeyContent, cloneString(log)))
+		if len(entry.Stack) > 0 {
+			innerKvs.AddFields(Any(KeyStack, entry.Stack))
+		}
+		ew := errorWriter{w: writer}
+		err := formatter.Format(&ew, innerKvs)
+		if ew.err != nil {
+			return ErrorOpWrite, ew.err
+		} else if err != nil {
+			return ErrorOpFormat, err
+		}
+		return "", nil
+	}
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+	b := appendTime(buf.b, timeFormatter, entry.Time)
+	b = append(b, " ["...)
+	if colorFlag == AutoColor {
+		b = append(b, selectLevelColor(entry.Level)...)
+		b = append(b, entry.Level.String()...)
+		b = append(b, ResetColor...)
 	} else {
-		writer.Write([]byte(fmt.Sprintf("%s [%s%s%s] %s %s%s",
-			l.timeFormatter(time.Now()), lvColor, LogTag[level], resetColor, caller, l.formatKeyValues(keyValues), log)))
+		b = append(b, entry.Level.String()...)
+	}
+	b = append(b, "] "...)
+	b = append(b, entry.Caller...)
+	b = append(b, ' ')
+	b = appendKeyValues(b, entry.KeyValues, timeFormatter)
+	b = append(b, log...)
+	if len(entry.Stack) > 0 {
+		b = append(b, entry.Stack.lines()...)
+	}
+	buf.b = b
+	if _, err := writer.Write(b); err != nil {
+		return ErrorOpWrite, err
+	}
+	return "", nil
+}
+
+var timeFormatPointer = reflect.ValueOf(TimeFormat).Pointer()
+
+// 使用timeFormatter格式化时间，默认的TimeFormat直接格式化到缓存
+func appendTime(buf []byte, timeFormatter func(t time.Time) string, t time.Time) []byte {
+	if reflect.ValueOf(timeFormatter).Pointer() == timeFormatPointer {
+		return t.AppendFormat(buf, timeFormatLayout)
 	}
+	return append(buf, timeFormatter(t)...)
 }
 
-func (l *logging) formatKeyValues(keyValues KeyValues) string {
+// 内置格式的附加信息：每个值后附加一个空格
+func appendKeyValues(buf []byte, keyValues KeyValues, timeFormatter func(t time.Time) string) []byte {
 	if keyValues == nil || keyValues.Len() == 0 {
-		return ""
+		return buf
 	}
 
-	buf := bytes.Buffer{}
+	if fs, ok := keyValues.(*Fields); ok {
+		for i := range *fs {
+			buf = appendTextField(buf, &(*fs)[i], timeFormatter)
+			buf = append(buf, ' ')
+		}
+		return buf
+	}
 	for _, k := range keyValues.Keys() {
-		buf.WriteString(l.formatValue(keyValues.Get(k)))
-		buf.WriteByte(' ')
+		buf = append(buf, formatTextValue(keyValues.Get(k), timeFormatter)...)
+		buf = append(buf, ' ')
 	}
-	return buf.String()
+	return buf
 }
 
-func (l *logging) formatValue(o interface{}) string {
+// 与formatTextValue(field.Value(), timeFormatter)相同，基础类型不转换为interface{}
+func appendTextField(buf []byte, field *Field, timeFormatter func(t time.Time) string) []byte {
+	switch field.Type {
+	case StringType:
+		return append(buf, field.String...)
+	case Int64Type:
+		return strconv.AppendInt(buf, field.Integer, 10)
+	case Uint64Type:
+		return strconv.AppendUint(buf, uint64(field.Integer), 10)
+	case Float64Type, Float32Type:
+		v, bitSize := field.float()
+		return strconv.AppendFloat(buf, v, 'g', -1, bitSize)
+	case BoolType:
+		return strconv.AppendBool(buf, field.Integer == 1)
+	case DurationType:
+		return append(buf, time.Duration(field.Integer).String()...)
+	case ErrorType:
+		return append(buf, field.Interface.(error).Error()...)
+	case TimeType:
+		if timeFormatter != nil {
+			return appendTime(buf, timeFormatter, field.time())
+		}
+		return appendTimeString(buf, field.time())
+	case UnknownType:
+		return buf
+	default:
+		return append(buf, formatTextValue(field.Value(), timeFormatter)...)
+	}
+}
+
+func formatTextValue(o interface{}, timeFormatter func(t time.Time) string) string {
 	if o == nil {
 		return ""
 	}
 
 	if t, ok := o.(time.Time); ok {
-		if l.timeFormatter != nil {
-			return l.timeFormatter(t)
+		if timeFormatter != nil {
+			return timeFormatter(t)
 		}
 	}
 	return formatValue(o, false)
 }
 
 func selectLevelColor(level Level) string {
-	if level == INFO {
-		return ForeCyan
-	} else if level == WARN {
-		return ForeYellow
-	} else if level < WARN {
+	if c, ok := loadLevels().colors[level]; ok {
+		return c
+	}
+	if level < WARN {
 		return ForeRed
 	}
 	return ""
 }
 
 func (l *logging) Logf(level Level, depth int, keyValues KeyValues, format string, args ...interface{}) {
-	if !l.IsEnabled(level) {
-		return
-	}
-
-	length := len(format)
-	if length > 0 {
-		if format[length-1] != '\n' {
-			format = format + "\n"
-		}
-	}
-	logInfo := fmt.Sprintf(format, args...)
-	w := l.selectWriter(level)
-	l.format(w, level, depth, keyValues, logInfo)
-
-	if level == PANIC {
-		l.panicFunc(NewKeyValues(KeyContent, logInfo))
-	} else if level <= FATAL {
-		l.processFatal(w)
-	}
-
-	//l.output(level, buf)
+	l.log(printfKind, level, depth+1, keyValues, format, args)
 }
 
 func (l *logging) Log(level Level, depth int, keyValues KeyValues, args ...interface{}) {
-	if !l.IsEnabled(level) {
-		return
-	}
-
-	logInfo := fmt.Sprint(args...)
-	w := l.selectWriter(level)
-	l.format(w, level, depth, keyValues, logInfo)
-
-	if level == PANIC {
-		l.panicFunc(NewKeyValues(KeyContent, logInfo))
-	} else if level <= FATAL {
-		l.processFatal(w)
-	}
+	l.log(printKind, level, depth+1, keyValues, "", args)
 }
 
 func (l *logging) Logln(level Level, depth int, keyValues KeyValues, args ...interface{}) {
-	if !l.IsEnabled(level) {
+	l.log(printlnKind, level, depth+1, keyValues, "", args)
+}
+
+// 日志内容格式化到池化的缓存，输出后放回，depth为Logf、Log、Logln的调用深度
+func (l *logging) log(kind int, level Level, depth int, keyValues KeyValues, format string, args []interface{}) {
+	name := loggerName(keyValues)
+	if !l.IsEnabledByName(name, level) {
 		return
 	}
 
-	logInfo := fmt.Sprintln(args...)
-	w := l.selectWriter(level)
-	l.format(w, level, depth, keyValues, logInfo)
+	var (
+		buf     *logBuffer
+		logInfo string
+		w       io.Writer
+	)
+	// 过滤器只决定是否输出日志，被拒绝的PANIC、FATAL日志仍然会panic或退出
+	if filters := l.loadFilters(); len(filters) > 0 {
+		entry := Entry{Level: level, Name: name, KeyValues: keyValues, msgFunc: func() string {
+			return sprintMessage(kind, format, args)
+		}}
+		deny := filterChain(filters, &entry) == FilterDeny
+		if deny && level > PANIC {
+			return
+		}
+		logInfo = entry.Message()
+		if !deny {
+			w = l.selectWriter(level)
+		}
+	} else {
+		buf = getBuffer()
+		appendMessage(buf, kind, format, args)
+		logInfo = bytesToString(buf.b)
+		w = l.selectWriter(level)
+	}
+	if w != nil {
+		if l.extractErrors {
+			keyValues = extractError(keyValues, args)
+		}
+		l.format(w, level, depth, keyValues, logInfo)
+	}
 
 	if level == PANIC {
+		if buf != nil {
+			logInfo = string(buf.b)
+		}
+		putBuffer(buf)
 		l.panicFunc(NewKeyValues(KeyContent, logInfo))
-	} else if level <= FATAL {
+		return
+	}
+	putBuffer(buf)
+	if level == FATAL {
 		l.processFatal(w)
 	}
 }
 
-func (l *logging) getBuffer() *bytes.Buffer {
-	buf := l.bufPool.Get().(*bytes.Buffer)
-	buf.Reset()
-	return buf
+func (l *logging) borrowsKeyValues() bool {
+	return true
 }
 
-func (l *logging) putBuffer(buf *bytes.Buffer) {
-	if buf == nil {
-		return
+func (l *logging) callerFrame(skip int) (runtime.Frame, bool) {
+	if l.callerSkip != nil {
+		return l.callerSkip.caller()
 	}
-	if buf.Len() > 256 {
-		//let big buffers die a natural death.
-		return
+	var pcs [1]uintptr
+	if runtime.Callers(skip+2, pcs[:]) == 0 {
+		return runtime.Frame{}, false
+	}
+	frame, _ := runtime.CallersFrames(pcs[:]).Next()
+	return frame, true
+}
+
+// 不检查日志级别，过滤器仍然有效
+func (l *logging) logEntry(level Level, keyValues KeyValues, msg string) {
+	if filters := l.loadFilters(); len(filters) > 0 {
+		entry := Entry{Level: level, Name: loggerName(keyValues), KeyValues: keyValues, msg: msg}
+		if filterChain(filters, &entry) == FilterDeny {
+			return
+		}
+	}
+	if w := l.selectWriter(level); w != nil {
+		l.format(w, level, 0, keyValues, msg)
 	}
-	l.bufPool.Put(buf)
 }
 
+// writer为nil时不输出协程调用栈
 func (l *logging) processFatal(writer io.Writer) {
-	if !l.fatalNoTrace {
+	if writer != nil && !l.fatalNoTrace {
 		trace := stacks(true)
 		writer.Write(trace)
 	}
+	handleCrash()
+	// 退出前同步已注册的Writer，避免丢失缓存的日志
+	syncWithTimeout(FatalSyncTimeout)
 	l.exitFunc(-1)
 }
 
@@ -403,22 +700,44 @@ func (l *logging) Clone() Logging {
 	ret := &logging{
 		timeFormatter:   l.timeFormatter,
 		callerFormatter: l.callerFormatter,
+		exitFunc:        l.exitFunc,
+		panicFunc:       l.panicFunc,
+		errorHandler:    l.errorHandler,
+		redaction:       l.redaction,
 		//formatter:     l.formatter,
-		colorFlag:    l.colorFlag,
-		fileFlag:     l.fileFlag,
+		colorFlag:    atomic.LoadInt32(&l.colorFlag),
+		fileFlag:     atomic.LoadInt32(&l.fileFlag),
+		callerSkip:   l.callerSkip,
+		callers:      &callerCache{},
+		staticFields: l.staticFields,
+		goroutineKey: l.goroutineKey,
 		fatalNoTrace: l.fatalNoTrace,
 		level:        l.level,
-		//writers:       map[Level]io.Writer{},
 
-		bufPool: sync.Pool{New: func() interface{} {
-			return bytes.NewBuffer(nil)
-		}},
+		withStack:     l.withStack,
+		stackLevel:    l.stackLevel,
+		extractErrors: l.extractErrors,
+		//writers:       map[Level]io.Writer{},
+	}
+	if f := l.formatter.Load(); f != nil {
+		ret.formatter.Store(f)
+	}
+	if v := l.levels.Load(); v != nil {
+		ret.levels.Store(v)
 	}
-	ret.formatter.Store(l.formatter.Load())
+	if v := l.appenders.Load(); v != nil {
+		ret.appenders.Store(v)
+	}
+	if v := l.filters.Load(); v != nil {
+		ret.filters.Store(v)
+	}
+	l.outputLock.Lock()
 	l.writers.Range(func(key, value interface{}) bool {
 		ret.writers.Store(key, value)
+		registerOutput(value.(io.Writer))
 		return true
 	})
+	l.outputLock.Unlock()
 	return ret
 }
 
@@ -437,8 +756,13 @@ func (l *logging) Clone() Logging {
 //	l.putBuffer(buf)
 //}
 
+// 选择日志级别对应的Writer，未配置时依次使用更低严重级别（已注册）的Writer
 func (l *logging) selectWriter(level Level) io.Writer {
-	for i := level; i <= DEBUG; i++ {
+	v, ok := l.writers.Load(level)
+	if ok && v != nil {
+		return v.(io.Writer)
+	}
+	for _, i := range levelsFrom(level) {
 		v, ok := l.writers.Load(i)
 		if ok && v != nil {
 			return v.(io.Writer)
@@ -447,38 +771,189 @@ func (l *logging) selectWriter(level Level) io.Writer {
 	return os.Stdout
 }
 
-func (l *logging) SetFormatter(f Formatter) {
-	l.formatter.Store(f)
+// atomic.Value只能保存相同类型的值，使用holder以支持更换不同类型的Formatter
+type formatterHolder struct {
+	f Formatter
+	// 按日志级别配置的Formatter，修改时复制
+	levels map[Level]Formatter
 }
 
-func (l *logging) GetFormatter() Formatter {
+func (l *logging) loadFormatter() formatterHolder {
 	v := l.formatter.Load()
 	if v == nil {
-		return nil
+		return formatterHolder{}
+	}
+	return v.(formatterHolder)
+}
+
+// 同时清除按日志级别配置的Formatter
+func (l *logging) SetFormatter(f Formatter) {
+	l.formatterLock.Lock()
+	defer l.formatterLock.Unlock()
+
+	l.formatter.Store(formatterHolder{f: f})
+}
+
+func (l *logging) GetFormatter() Formatter {
+	return l.loadFormatter().f
+}
+
+func (l *logging) SetFormatterBySeverity(severityLevel Level, f Formatter) {
+	l.formatterLock.Lock()
+	defer l.formatterLock.Unlock()
+
+	old := l.loadFormatter()
+	levels := make(map[Level]Formatter, len(old.levels)+1)
+	for k, v := range old.levels {
+		levels[k] = v
+	}
+	if f == nil {
+		delete(levels, severityLevel)
+	} else {
+		levels[severityLevel] = f
+	}
+	if len(levels) == 0 {
+		levels = nil
 	}
-	return v.(Formatter)
+	l.formatter.Store(formatterHolder{f: old.f, levels: levels})
+}
+
+func (l *logging) GetFormatterBySeverity(severityLevel Level) Formatter {
+	return l.loadFormatter().levels[severityLevel]
+}
+
+// 与selectWriter相同，级别未配置时使用下一个配置了的较轻的级别，都未配置时使用SetFormatter设置的Formatter
+func (l *logging) selectFormatter(level Level) Formatter {
+	holder := l.loadFormatter()
+	if holder.levels == nil {
+		return holder.f
+	}
+	if f, ok := holder.levels[level]; ok {
+		return f
+	}
+	for _, i := range levelsFrom(level) {
+		if f, ok := holder.levels[i]; ok {
+			return f
+		}
+	}
+	return holder.f
 }
 
 func (l *logging) SetSeverityLevel(severity Level) {
-	atomic.StoreInt32(&l.level, severity)
+	atomic.StoreInt32((*int32)(&l.level), int32(severity))
+}
+
+func (l *logging) getLevel() Level {
+	return Level(atomic.LoadInt32((*int32)(&l.level)))
+}
+
+func (l *logging) SetCallerFlag(flag int) {
+	atomic.StoreInt32(&l.fileFlag, int32(flag))
+}
+
+func (l *logging) getCallerFlag() int {
+	return int(atomic.LoadInt32(&l.fileFlag))
+}
+
+func (l *logging) SetColorFlag(flag int) {
+	atomic.StoreInt32(&l.colorFlag, int32(flag))
+}
+
+func (l *logging) getColorFlag() int {
+	return int(atomic.LoadInt32(&l.colorFlag))
 }
 
 func (l *logging) IsEnabled(severityLevel Level) bool {
-	return atomic.LoadInt32(&l.level) >= severityLevel
+	return l.getLevel() >= severityLevel
+}
+
+func (l *logging) SetSeverityLevelByName(name string, severity Level) {
+	if isRootLoggerName(name) {
+		l.SetSeverityLevel(severity)
+		return
+	}
+	l.levelLock.Lock()
+	defer l.levelLock.Unlock()
+
+	old := l.loadLevels()
+	levels := make(levelTree, len(old)+1)
+	for k, v := range old {
+		levels[k] = v
+	}
+	levels[name] = severity
+	l.levels.Store(levels)
+}
+
+func (l *logging) SetSeverityLevels(levels map[string]Level) {
+	l.levelLock.Lock()
+	defer l.levelLock.Unlock()
+
+	tree := make(levelTree, len(levels))
+	for k, v := range levels {
+		if isRootLoggerName(k) {
+			l.SetSeverityLevel(v)
+		} else {
+			tree[k] = v
+		}
+	}
+	l.levels.Store(tree)
+}
+
+func (l *logging) GetSeverityLevels() map[string]Level {
+	levels := l.loadLevels()
+	ret := make(map[string]Level, len(levels)+1)
+	for k, v := range levels {
+		ret[k] = v
+	}
+	ret[RootLoggerName] = l.getLevel()
+	return ret
+}
+
+func (l *logging) IsEnabledByName(name string, severityLevel Level) bool {
+	if name != "" {
+		if lv, ok := l.loadLevels().find(name); ok {
+			return lv >= severityLevel
+		}
+	}
+	return l.IsEnabled(severityLevel)
+}
+
+func (l *logging) loadLevels() levelTree {
+	v := l.levels.Load()
+	if v == nil {
+		return nil
+	}
+	return v.(levelTree)
 }
 
 // Logging不会自动为输出的Writer加锁，如果需要加锁请使用LockedWriter：
 // logging.SetOutPut(&writer.LockedWriter{w})
 func (l *logging) SetOutput(w io.Writer) {
-	for i := FATAL; i <= DEBUG; i++ {
-		l.writers.Store(i, w)
+	l.outputLock.Lock()
+	defer l.outputLock.Unlock()
+
+	for _, i := range Levels() {
+		l.storeOutput(i, w)
 	}
 }
 
 // Logging不会自动为输出的Writer加锁，如果需要加锁请使用LockedWriter：
 // logging.SetOutputBySeverity(level, &writer.LockedWriter{w})
 func (l *logging) SetOutputBySeverity(severityLevel Level, w io.Writer) {
+	l.outputLock.Lock()
+	defer l.outputLock.Unlock()
+
+	l.storeOutput(severityLevel, w)
+}
+
+// 替换级别的Writer，自动注册新的Writer并释放被替换的Writer，需持有outputLock
+func (l *logging) storeOutput(severityLevel Level, w io.Writer) {
+	old, ok := l.writers.Load(severityLevel)
 	l.writers.Store(severityLevel, w)
+	registerOutput(w)
+	if ok {
+		releaseOutput(old.(io.Writer))
+	}
 }
 
 func (l *logging) GetOutputBySeverity(severityLevel Level) io.Writer {
@@ -489,6 +964,74 @@ func (l *logging) GetOutputBySeverity(severityLevel Level) io.Writer {
 	return v.(io.Writer)
 }
 
+func (l *logging) AddAppender(appender Appender) {
+	if appender == nil {
+		return
+	}
+	l.appenderLock.Lock()
+	defer l.appenderLock.Unlock()
+
+	old := l.loadAppenders()
+	appenders := make([]Appender, len(old), len(old)+1)
+	copy(appenders, old)
+	l.appenders.Store(append(appenders, appender))
+}
+
+func (l *logging) RemoveAppender(appender Appender) {
+	if appender == nil {
+		return
+	}
+	l.appenderLock.Lock()
+	defer l.appenderLock.Unlock()
+
+	old := l.loadAppenders()
+	appenders := make([]Appender, 0, len(old))
+	for _, v := range old {
+		if v != appender {
+			appenders = append(appenders, v)
+		}
+	}
+	if len(appenders) != len(old) {
+		l.appenders.Store(appenders)
+	}
+}
+
+func (l *logging) GetAppenders() []Appender {
+	old := l.loadAppenders()
+	ret := make([]Appender, len(old))
+	copy(ret, old)
+	return ret
+}
+
+func (l *logging) loadAppenders() []Appender {
+	v := l.appenders.Load()
+	if v == nil {
+		return nil
+	}
+	return v.([]Appender)
+}
+
+func (l *logging) AddFilter(filter Filter) {
+	if filter == nil {
+		return
+	}
+	l.filterLock.Lock()
+	defer l.filterLock.Unlock()
+
+	old := l.loadFilters()
+	filters := make([]Filter, len(old), len(old)+1)
+	copy(filters, old)
+	l.filters.Store(append(filters, filter))
+}
+
+func (l *logging) loadFilters() []Filter {
+	v := l.filters.Load()
+	if v == nil {
+		return nil
+	}
+	return v.([]Filter)
+}
+
 func shortFile(file string) string {
 	short := file
 	for i := len(file) - 1; i > 0; i-- {
@@ -500,6 +1043,53 @@ func shortFile(file string) string {
 	return short
 }
 
+// 附加的日志内容中没有KeyError时将参数中的第一个error添加为KeyError，
+// 并将所有error类型的附加信息的错误链添加为"Key+Causes"（如KeyErrorCauses），有修改时返回新的KeyValues
+func extractError(keyValues KeyValues, args []interface{}) KeyValues {
+	var (
+		err    error
+		causes []Field
+	)
+	if keyValues == nil || keyValues.Get(KeyError) == nil {
+		for _, v := range args {
+			if e, ok := v.(error); ok && e != nil {
+				err = e
+				break
+			}
+		}
+	}
+	if keyValues != nil {
+		for it := keyValues.Iterator(); it.HasNext(); {
+			k, v := it.Next()
+			if e, ok := v.(error); ok && e != nil && keyValues.Get(k+errorCausesSuffix) == nil {
+				if c := errorCauses(e); len(c) > 0 {
+					causes = append(causes, Any(k+errorCausesSuffix, c))
+				}
+			}
+		}
+	}
+	if err != nil {
+		if c := errorCauses(err); len(c) > 0 {
+			causes = append(causes, Any(KeyErrorCauses, c))
+		}
+	}
+	if err == nil && len(causes) == 0 {
+		return keyValues
+	}
+
+	size := len(causes) + 1
+	if keyValues != nil {
+		size += keyValues.Len()
+	}
+	ret := make(Fields, 0, size)
+	MergeKeyValues(&ret, keyValues)
+	if err != nil {
+		ret.AddFields(Err(err))
+	}
+	ret.AddFields(causes...)
+	return &ret
+}
+
 func stacks(all bool) []byte {
 	n := 10000
 	if all {
@@ -516,8 +1106,11 @@ func stacks(all bool) []byte {
 	return trace
 }
 
+// 默认的时间格式
+const timeFormatLayout = "2006-01-02 15:04:05"
+
 func TimeFormat(t time.Time) string {
-	var timeString = t.Format("2006-01-02 15:04:05")
+	var timeString = t.Format(timeFormatLayout)
 	return timeString
 }
 
@@ -604,17 +1197,54 @@ func simpleFuncName(funcName string) string {
 	return buf.String()
 }
 
+// 配置内置Logging实现的写入及格式化失败的处理函数
+func SetErrorHandler(h ErrorHandler) func(*logging) {
+	return func(logging *logging) {
+		logging.errorHandler = h
+	}
+}
+
+// 配置内置Logging实现的脱敏规则，在Formatter及Appender输出之前对附加的日志内容及日志内容脱敏
+func SetRedaction(r *Redaction) func(*logging) {
+	return func(logging *logging) {
+		logging.redaction = r
+	}
+}
+
+// 配置内置Logging实现附加调用栈（KeyStack）的日志级别，日志级别达到（同样或更严重）该级别时附加当前协程的调用栈
+func SetStackTraceLevel(severityLevel Level) func(*logging) {
+	return func(logging *logging) {
+		logging.withStack = true
+		logging.stackLevel = severityLevel
+	}
+}
+
+// 配置内置Logging实现是否将日志参数中的第一个error添加为附加的日志内容（KeyError），
+// 并将所有error类型的附加信息的错误链添加为Key加上"Causes"后缀的字符串数组（如KeyError对应KeyErrorCauses），默认不添加
+func SetExtractErrors(extract bool) func(*logging) {
+	return func(logging *logging) {
+		logging.extractErrors = extract
+	}
+}
+
 // 配置内置Logging实现的颜色的标志，有AutoColor、DisableColor、ForceColor
 func SetColorFlag(flag int) func(*logging) {
 	return func(logging *logging) {
-		logging.colorFlag = flag
+		logging.colorFlag = int32(flag)
 	}
 }
 
 // 配置内置Logging实现的文件输出标志，有ShortFile、LongFile
 func SetCallerFlag(flag int) func(*logging) {
 	return func(logging *logging) {
-		logging.fileFlag = flag
+		logging.fileFlag = int32(flag)
+	}
+}
+
+// 配置内置Logging实现按日志名称配置的日志级别，参见Logging.SetSeverityLevels
+func SetLevelsByName(levels map[string]Level) func(*logging) {
+	return func(logging *logging) {
+		logging.SetSeverityLevels(levels)
 	}
 }
 
@@ -630,11 +1260,31 @@ func SetFormatter(f Formatter) {
 	defaultLogging.Load().(Logging).SetFormatter(f)
 }
 
+// 获得默认Logging的日志格式化工具
+func GetFormatter() Formatter {
+	return defaultLogging.Load().(Logging).GetFormatter()
+}
+
+// 设置默认Logging对应日志级别的日志格式化工具
+func SetFormatterBySeverity(severity Level, f Formatter) {
+	defaultLogging.Load().(Logging).SetFormatterBySeverity(severity, f)
+}
+
 // 设置默认Logging的日志严重级别
 func SetSeverityLevel(severity Level) {
 	defaultLogging.Load().(Logging).SetSeverityLevel(severity)
 }
 
+// 获得默认Logging的日志严重级别
+func GetSeverityLevel() Level {
+	return defaultLogging.Load().(Logging).GetSeverityLevel()
+}
+
+// 设置默认Logging日志名称对应的日志严重级别
+func SetSeverityLevelByName(name string, severity Level) {
+	defaultLogging.Load().(Logging).SetSeverityLevelByName(name, severity)
+}
+
 // 检查是否输出参数级别的日志
 func IsEnabled(severityLevel Level) bool {
 	return defaultLogging.Load().(Logging).IsEnabled(severityLevel)
@@ -650,6 +1300,21 @@ func SetOutputBySeverity(severity Level, w io.Writer) {
 	defaultLogging.Load().(Logging).SetOutputBySeverity(severity, w)
 }
 
+// 为默认Logging添加Appender
+func AddAppender(appender Appender) {
+	defaultLogging.Load().(Logging).AddAppender(appender)
+}
+
+// 删除默认Logging的Appender
+func RemoveAppender(appender Appender) {
+	defaultLogging.Load().(Logging).RemoveAppender(appender)
+}
+
+// 为默认Logging添加过滤器
+func AddFilter(filter Filter) {
+	defaultLogging.Load().(Logging).AddFilter(filter)
+}
+
 // 获得默认Logging对应日志级别的输出
 func GetOutputBySeverity(severity Level) io.Writer {
 	return defaultLogging.Load().(Logging).GetOutputBySeverity(severity)
@@ -0,0 +1,91 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package xlog
+
+import (
+	"io"
+	"time"
+)
+
+// Logging配置的快照，通过Logging.Config()获得，修改快照不影响Logging
+type LoggingConfig struct {
+	// 日志严重级别，等同于Levels中RootLoggerName对应的级别
+	Level Level
+	// 所有日志名称对应的日志级别，参见Logging.GetSeverityLevels
+	Levels map[string]Level
+
+	// 日志格式化工具，为nil时使用内置格式
+	Formatter Formatter
+	// 按日志级别配置的格式化工具，参见Logging.SetFormatterBySeverity
+	Formatters map[Level]Formatter
+	// 颜色的标志，参见SetColorFlag
+	ColorFlag int
+	// 调用者的输出标志，参见SetCallerFlag
+	CallerFlag int
+	// 发生致命错误时是否不打印堆栈，参见SetFatalNoTrace
+	FatalNoTrace bool
+	// 是否附加调用栈及附加调用栈的日志级别，参见SetStackTraceLevel
+	WithStack  bool
+	StackLevel Level
+	// 是否将参数中的error添加为附加的日志内容，参见SetExtractErrors
+	ExtractErrors bool
+	// 每条日志附加的静态信息，参见SetStaticFields
+	StaticFields Fields
+
+	// 配置了Writer的日志级别及对应的Writer，未配置的级别按SetOutputBySeverity的规则选择
+	Writers   map[Level]io.Writer
+	Appenders []Appender
+	Filters   []Filter
+
+	TimeFormatter   func(t time.Time) string
+	CallerFormatter func(file string, line int, funcName string) string
+	ExitFunc        ExitFunc
+	PanicFunc       PanicFunc
+	ErrorHandler    ErrorHandler
+	Redaction       *Redaction
+}
+
+func (l *logging) GetSeverityLevel() Level {
+	return l.getLevel()
+}
+
+func (l *logging) Config() LoggingConfig {
+	formatter := l.loadFormatter()
+	ret := LoggingConfig{
+		Level:         l.getLevel(),
+		Levels:        l.GetSeverityLevels(),
+		Formatter:     formatter.f,
+		Formatters:    map[Level]Formatter{},
+		ColorFlag:     l.getColorFlag(),
+		CallerFlag:    l.getCallerFlag(),
+		FatalNoTrace:  l.fatalNoTrace,
+		WithStack:     l.withStack,
+		StackLevel:    l.stackLevel,
+		ExtractErrors: l.extractErrors,
+		StaticFields:  append(Fields(nil), l.staticFields...),
+
+		Writers:   map[Level]io.Writer{},
+		Appenders: l.GetAppenders(),
+		Filters:   append([]Filter(nil), l.loadFilters()...),
+
+		TimeFormatter:   l.timeFormatter,
+		CallerFormatter: l.callerFormatter,
+		ExitFunc:        l.exitFunc,
+		PanicFunc:       l.panicFunc,
+		ErrorHandler:    l.errorHandler,
+		Redaction:       l.redaction,
+	}
+	for k, v := range formatter.levels {
+		ret.Formatters[k] = v
+	}
+	l.writers.Range(func(key, value interface{}) bool {
+		if w, ok := value.(io.Writer); ok && w != nil {
+			ret.Writers[key.(Level)] = w
+		}
+		return true
+	})
+	return ret
+}
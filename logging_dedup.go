@@ -0,0 +1,399 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package xlog
+
+import (
+	"io"
+	"reflect"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// 重复日志数量的Key，附加在汇总日志中
+	KeyRepeated = "LogRepeated"
+)
+
+// 默认的重复日志合并时间窗口
+var DefaultDedupWindow = 10 * time.Second
+
+type DedupOpt func(l *DedupLogging)
+
+// 合并连续重复日志的Logging，使用NewDedupLogging创建
+type DedupLogging struct {
+	logging Logging
+	window  time.Duration
+
+	lock  sync.Mutex
+	level Level
+	// 上一条日志的输出方式、format及参数，比较时不格式化
+	kind      int
+	format    string
+	args      []interface{}
+	keyValues KeyValues
+	start     time.Time
+	repeated  uint64
+	last      recordedKeyValues
+	timer     *time.Timer
+	// 汇总日志输出后递增，用于忽略过期的定时器
+	seq uint64
+
+	suppressed uint64
+}
+
+// 合并连续重复日志的Logging：时间窗口内与上一条日志级别、format、参数及附加信息相同（不比较时间）的日志不输出，
+// 窗口结束时（或出现不同的日志时）输出一条汇总日志："last message repeated N times"，
+// 汇总日志的调用者为最后一条重复日志的调用者，重复数量以KeyRepeated附加。PANIC及FATAL日志不合并，
+// 比较时不格式化参数，不对延迟求值（Lazy）的参数及附加信息求值，包含延迟求值参数或附加信息的日志不合并
+// Param： logging实际输出的Logging，opts配置
+func NewDedupLogging(logging Logging, opts ...DedupOpt) *DedupLogging {
+	ret := &DedupLogging{
+		logging: logging,
+		window:  DefaultDedupWindow,
+	}
+	for _, v := range opts {
+		v(ret)
+	}
+	return ret
+}
+
+// 配置合并重复日志的时间窗口，从第一条输出的日志开始计算
+func SetDedupWindow(window time.Duration) DedupOpt {
+	return func(l *DedupLogging) {
+		l.window = window
+	}
+}
+
+// 获得累计被合并（未输出）的日志数量（线程安全）
+func (l *DedupLogging) Suppressed() uint64 {
+	return atomic.LoadUint64(&l.suppressed)
+}
+
+// 立即输出等待中的汇总日志（线程安全）
+func (l *DedupLogging) Flush() {
+	l.lock.Lock()
+	summary := l.takeSummary()
+	l.reset()
+	l.lock.Unlock()
+
+	summary.output(l.logging)
+}
+
+// 等待输出的汇总日志，在锁内获得，在锁外输出
+type dedupSummary struct {
+	level    Level
+	repeated uint64
+	last     recordedKeyValues
+}
+
+func (s *dedupSummary) output(logging Logging) {
+	if s == nil {
+		return
+	}
+	kvs := s.last.KeyValues.Clone()
+	addFields(kvs, []Field{Uint64(KeyRepeated, s.repeated)})
+	last := s.last
+	last.KeyValues = kvs
+	last.time = time.Now()
+	logging.Logf(s.level, 0, &last, "last message repeated %d times", s.repeated)
+}
+
+// 获得等待中的汇总日志并清空重复计数，没有重复日志时返回nil（需持有锁）
+func (l *DedupLogging) takeSummary() *dedupSummary {
+	if l.repeated == 0 {
+		return nil
+	}
+	if l.timer != nil {
+		l.timer.Stop()
+		l.timer = nil
+	}
+	ret := &dedupSummary{
+		level:    l.level,
+		repeated: l.repeated,
+		last:     l.last,
+	}
+	l.repeated = 0
+	l.last = recordedKeyValues{}
+	l.seq++
+	return ret
+}
+
+func (l *DedupLogging) reset() {
+	l.format = ""
+	l.args = nil
+	l.keyValues = nil
+	l.start = time.Time{}
+}
+
+// 判断是否为重复日志，不是重复日志时输出之前的汇总日志并调用output输出，
+// 锁内只更新状态，汇总日志及output在锁外输出
+func (l *DedupLogging) dedup(level Level, depth int, keyValues KeyValues, kind int, format string, args []interface{}, output func()) {
+	l.lock.Lock()
+	now := time.Now()
+	if !l.start.IsZero() && level == l.level && kind == l.kind && format == l.format && now.Sub(l.start) < l.window &&
+		sameArgs(args, l.args) && sameKeyValues(keyValues, l.keyValues) {
+		atomic.AddUint64(&l.suppressed, 1)
+		l.repeated++
+		// keyValues可能在调用返回后放回池中（参见borrowsKeyValues），保存时复制
+		if keyValues == nil {
+			keyValues = NewFields()
+		} else {
+			keyValues = keyValues.Clone()
+		}
+		l.last = recordedKeyValues{KeyValues: keyValues}
+		frame, ok := recordCaller(l.logging, 2+depth)
+		l.last.pc, l.last.function, l.last.file, l.last.line, l.last.ok = frame.PC, frame.Function, frame.File, frame.Line, ok
+		if l.timer == nil {
+			seq := l.seq
+			l.timer = time.AfterFunc(l.start.Add(l.window).Sub(now), func() {
+				l.expire(seq)
+			})
+		}
+		l.lock.Unlock()
+		return
+	}
+
+	summary := l.takeSummary()
+	l.level = level
+	l.kind = kind
+	l.format = format
+	l.args = append(l.args[:0:0], args...)
+	if keyValues != nil {
+		keyValues = keyValues.Clone()
+	}
+	l.keyValues = keyValues
+	l.start = now
+	l.lock.Unlock()
+
+	summary.output(l.logging)
+	output()
+}
+
+func (l *DedupLogging) expire(seq uint64) {
+	l.lock.Lock()
+	if seq != l.seq {
+		l.lock.Unlock()
+		return
+	}
+	l.timer = nil
+	summary := l.takeSummary()
+	l.reset()
+	l.lock.Unlock()
+
+	summary.output(l.logging)
+}
+
+// 比较附加信息，不对延迟求值（Lazy）的值求值，包含延迟求值的附加信息视为不同
+func sameKeyValues(a, b KeyValues) bool {
+	if a == b {
+		return true
+	}
+	if a == nil || b == nil {
+		return (a == nil || a.Len() == 0) && (b == nil || b.Len() == 0)
+	}
+	if a.Len() != b.Len() {
+		return false
+	}
+	fa, ok := a.(*Fields)
+	fb, ok2 := b.(*Fields)
+	if ok && ok2 {
+		for i := range *fa {
+			if !sameField(&(*fa)[i], &(*fb)[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	for _, k := range a.Keys() {
+		if !sameValue(a.Get(k), b.Get(k)) {
+			return false
+		}
+	}
+	return true
+}
+
+func sameField(a, b *Field) bool {
+	if a.Key != b.Key || a.Type != b.Type || a.Integer != b.Integer || a.String != b.String {
+		return false
+	}
+	if a.Interface == nil && b.Interface == nil {
+		return true
+	}
+	return sameValue(a.Interface, b.Interface)
+}
+
+func sameArgs(a, b []interface{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !sameValue(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// 不格式化地比较值：基础类型直接比较，error比较错误信息，其他类型使用reflect.DeepEqual，函数（包括Lazy）视为不同
+func sameValue(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	ta := reflect.TypeOf(a)
+	if ta != reflect.TypeOf(b) {
+		return false
+	}
+	if ea, ok := a.(error); ok {
+		return ea.Error() == b.(error).Error()
+	}
+	switch ta.Kind() {
+	case reflect.Func:
+		return false
+	case reflect.Struct, reflect.Array, reflect.Ptr, reflect.Slice, reflect.Map:
+		return reflect.DeepEqual(a, b)
+	default:
+		return a == b
+	}
+}
+
+func (l *DedupLogging) Logf(level Level, depth int, keyValues KeyValues, format string, args ...interface{}) {
+	if level <= PANIC || !l.logging.IsEnabledByName(loggerName(keyValues), level) {
+		l.logging.Logf(level, depth+1, keyValues, format, args...)
+		return
+	}
+	l.dedup(level, depth, keyValues, printfKind, format, args, func() {
+		l.logging.Logf(level, depth+3, keyValues, format, args...)
+	})
+}
+
+func (l *DedupLogging) Log(level Level, depth int, keyValues KeyValues, args ...interface{}) {
+	if level <= PANIC || !l.logging.IsEnabledByName(loggerName(keyValues), level) {
+		l.logging.Log(level, depth+1, keyValues, args...)
+		return
+	}
+	l.dedup(level, depth, keyValues, printKind, "", args, func() {
+		l.logging.Log(level, depth+3, keyValues, args...)
+	})
+}
+
+func (l *DedupLogging) Logln(level Level, depth int, keyValues KeyValues, args ...interface{}) {
+	if level <= PANIC || !l.logging.IsEnabledByName(loggerName(keyValues), level) {
+		l.logging.Logln(level, depth+1, keyValues, args...)
+		return
+	}
+	l.dedup(level, depth, keyValues, printlnKind, "", args, func() {
+		l.logging.Logln(level, depth+3, keyValues, args...)
+	})
+}
+
+func (l *DedupLogging) borrowsKeyValues() bool {
+	return borrowsKeyValues(l.logging)
+}
+
+func (l *DedupLogging) callerFrame(skip int) (runtime.Frame, bool) {
+	return recordCaller(l.logging, skip+1)
+}
+
+func (l *DedupLogging) logEntry(level Level, keyValues KeyValues, msg string) {
+	replayEntry(l.logging, level, keyValues, msg)
+}
+
+func (l *DedupLogging) SetFormatter(f Formatter) {
+	l.logging.SetFormatter(f)
+}
+
+func (l *DedupLogging) GetFormatter() Formatter {
+	return l.logging.GetFormatter()
+}
+
+func (l *DedupLogging) SetFormatterBySeverity(severityLevel Level, f Formatter) {
+	l.logging.SetFormatterBySeverity(severityLevel, f)
+}
+
+func (l *DedupLogging) GetFormatterBySeverity(severityLevel Level) Formatter {
+	return l.logging.GetFormatterBySeverity(severityLevel)
+}
+
+func (l *DedupLogging) SetSeverityLevel(severityLevel Level) {
+	l.logging.SetSeverityLevel(severityLevel)
+}
+
+func (l *DedupLogging) GetSeverityLevel() Level {
+	return l.logging.GetSeverityLevel()
+}
+
+func (l *DedupLogging) SetCallerFlag(flag int) {
+	l.logging.SetCallerFlag(flag)
+}
+
+func (l *DedupLogging) SetColorFlag(flag int) {
+	l.logging.SetColorFlag(flag)
+}
+
+func (l *DedupLogging) IsEnabled(severityLevel Level) bool {
+	return l.logging.IsEnabled(severityLevel)
+}
+
+func (l *DedupLogging) SetSeverityLevelByName(name string, severityLevel Level) {
+	l.logging.SetSeverityLevelByName(name, severityLevel)
+}
+
+func (l *DedupLogging) SetSeverityLevels(levels map[string]Level) {
+	l.logging.SetSeverityLevels(levels)
+}
+
+func (l *DedupLogging) GetSeverityLevels() map[string]Level {
+	return l.logging.GetSeverityLevels()
+}
+
+func (l *DedupLogging) IsEnabledByName(name string, severityLevel Level) bool {
+	return l.logging.IsEnabledByName(name, severityLevel)
+}
+
+func (l *DedupLogging) SetOutput(w io.Writer) {
+	l.logging.SetOutput(w)
+}
+
+func (l *DedupLogging) SetOutputBySeverity(severityLevel Level, w io.Writer) {
+	l.logging.SetOutputBySeverity(severityLevel, w)
+}
+
+func (l *DedupLogging) GetOutputBySeverity(severity Level) io.Writer {
+	return l.logging.GetOutputBySeverity(severity)
+}
+
+func (l *DedupLogging) AddAppender(appender Appender) {
+	l.logging.AddAppender(appender)
+}
+
+func (l *DedupLogging) RemoveAppender(appender Appender) {
+	l.logging.RemoveAppender(appender)
+}
+
+func (l *DedupLogging) GetAppenders() []Appender {
+	return l.logging.GetAppenders()
+}
+
+func (l *DedupLogging) AddFilter(filter Filter) {
+	l.logging.AddFilter(filter)
+}
+
+func (l *DedupLogging) GetErrorStats() ErrorStats {
+	return l.logging.GetErrorStats()
+}
+
+func (l *DedupLogging) Config() LoggingConfig {
+	return l.logging.Config()
+}
+
+func (l *DedupLogging) Clone() Logging {
+	return &DedupLogging{
+		logging: l.logging.Clone(),
+		window:  l.window,
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package xlog
+
+import (
+	"io"
+	"sync/atomic"
+)
+
+const (
+	// 写入Writer失败
+	ErrorOpWrite = "write"
+	// Formatter格式化失败
+	ErrorOpFormat = "format"
+)
+
+// 日志输出失败的处理函数，参数err类型为*LoggingError，在日志调用的协程中同步调用，不应阻塞
+type ErrorHandler func(err error)
+
+// 日志输出失败的错误
+type LoggingError struct {
+	// 失败的操作，ErrorOpWrite或ErrorOpFormat
+	Op string
+	// 日志级别
+	Level Level
+	// 原始错误
+	Err error
+}
+
+func (e *LoggingError) Error() string {
+	return "xlog: " + e.Op + " " + e.Level.String() + " log failed: " + e.Err.Error()
+}
+
+func (e *LoggingError) Unwrap() error {
+	return e.Err
+}
+
+// 日志输出失败的统计
+type ErrorStats struct {
+	// 写入Writer失败的次数
+	WriteErrors uint64
+	// Formatter格式化失败的次数
+	FormatErrors uint64
+}
+
+type errorCounter struct {
+	writeErrors  uint64
+	formatErrors uint64
+}
+
+func (c *errorCounter) stats() ErrorStats {
+	return ErrorStats{
+		WriteErrors:  atomic.LoadUint64(&c.writeErrors),
+		FormatErrors: atomic.LoadUint64(&c.formatErrors),
+	}
+}
+
+// 记录Writer写入的错误，供区分Formatter返回的错误
+type errorWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (w *errorWriter) Write(d []byte) (int, error) {
+	n, err := w.w.Write(d)
+	if err != nil && w.err == nil {
+		w.err = err
+	}
+	return n, err
+}
+
+func (l *logging) handleError(op string, level Level, err error) {
+	if op == ErrorOpFormat {
+		atomic.AddUint64(&l.errors.formatErrors, 1)
+	} else {
+		atomic.AddUint64(&l.errors.writeErrors, 1)
+	}
+	if l.errorHandler != nil {
+		l.errorHandler(&LoggingError{Op: op, Level: level, Err: err})
+	}
+}
+
+func (l *logging) GetErrorStats() ErrorStats {
+	return l.errors.stats()
+}
@@ -0,0 +1,422 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package xlog
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// 缓存超出限制被丢弃的日志数量的Key，附加在回放的第一条日志中
+	KeyBufferDropped = "LogBufferDropped"
+)
+
+// 默认的缓存配置：缓存DEBUG及以上级别的日志，出现ERROR及以上级别的日志时输出，最多缓存1000条、1MB
+var (
+	DefaultFingersCrossedTrigger    = ERROR
+	DefaultFingersCrossedLevel      = DEBUG
+	DefaultFingersCrossedMaxEntries = 1000
+	DefaultFingersCrossedMaxBytes   = 1 << 20
+)
+
+type FingersCrossedOpt func(l *FingersCrossedLogging)
+
+// 回放缓存的日志时使用，保存记录时的时间及调用者
+type recordedKeyValues struct {
+	KeyValues
+	time     time.Time
+	pc       uintptr
+	function string
+	file     string
+	line     int
+	ok       bool
+}
+
+// 内置Logging及包装Logging的实现，用于缓存及回放日志
+type entryLogging interface {
+	// 获得调用者，skip为0时为callerFrame的调用者，配置了按包路径跳过调用者时忽略skip
+	callerFrame(skip int) (runtime.Frame, bool)
+	// 输出已格式化的日志内容，不检查日志级别
+	logEntry(level Level, keyValues KeyValues, msg string)
+}
+
+// 使用logging的调用者配置获得调用者，skip为0时为recordCaller的调用者
+func recordCaller(logging Logging, skip int) (runtime.Frame, bool) {
+	if el, ok := logging.(entryLogging); ok {
+		return el.callerFrame(skip + 1)
+	}
+	var pcs [1]uintptr
+	if runtime.Callers(skip+2, pcs[:]) == 0 {
+		return runtime.Frame{}, false
+	}
+	frame, _ := runtime.CallersFrames(pcs[:]).Next()
+	return frame, true
+}
+
+// 输出缓存的日志，不检查logging的日志级别；非xlog实现的logging只能按其日志级别输出
+func replayEntry(logging Logging, level Level, keyValues KeyValues, msg string) {
+	if el, ok := logging.(entryLogging); ok {
+		el.logEntry(level, keyValues, msg)
+		return
+	}
+	logging.Log(level, 1, keyValues, msg)
+}
+
+type bufferedEntry struct {
+	level     Level
+	keyValues recordedKeyValues
+	msg       string
+}
+
+// 缓存日志的Logging，使用NewFingersCrossedLogging或NewFingersCrossedLogger创建
+type FingersCrossedLogging struct {
+	logging    Logging
+	trigger    Level
+	level      Level
+	maxEntries int
+	maxBytes   int
+
+	lock      sync.Mutex
+	triggered int32
+	entries   []bufferedEntry
+	size      int
+	dropped   uint64
+}
+
+// 缓存日志的Logging，用于一个工作单元（如一次请求）：
+// 低于触发级别的日志缓存在内存中，工作单元成功时调用Discard丢弃；
+// 出现触发级别（默认ERROR）及以上的日志时按顺序输出所有缓存的日志（不受logging日志级别的限制，保留记录时的时间及调用者），
+// 之后的日志直接输出到logging。缓存超出条数或字节数（日志内容长度）限制时丢弃最早的日志
+// Param： logging实际输出的Logging，opts缓存配置
+func NewFingersCrossedLogging(logging Logging, opts ...FingersCrossedOpt) *FingersCrossedLogging {
+	ret := &FingersCrossedLogging{
+		logging:    logging,
+		trigger:    DefaultFingersCrossedTrigger,
+		level:      DefaultFingersCrossedLevel,
+		maxEntries: DefaultFingersCrossedMaxEntries,
+		maxBytes:   DefaultFingersCrossedMaxBytes,
+	}
+	for _, v := range opts {
+		v(ret)
+	}
+	return ret
+}
+
+// 从已有的Logger创建缓存日志的Logger，保留Logger的名称及附加信息，返回的Logger派生的Logger共享同一缓存。
+// logger必须为xlog创建的Logger，否则返回logger本身及nil
+func NewFingersCrossedLogger(logger Logger, opts ...FingersCrossedOpt) (Logger, *FingersCrossedLogging) {
+	logging, ok := getLoggerLogging(logger)
+	if !ok {
+		return logger, nil
+	}
+	fc := NewFingersCrossedLogging(logging, opts...)
+	ret, _ := rebindLogger(logger, fc)
+	return ret, fc
+}
+
+// 配置触发输出的日志级别，PANIC及FATAL总是触发输出
+func SetFingersCrossedTrigger(level Level) FingersCrossedOpt {
+	return func(l *FingersCrossedLogging) {
+		l.trigger = level
+	}
+}
+
+// 配置缓存的日志级别，低于该级别的日志不缓存
+func SetFingersCrossedLevel(level Level) FingersCrossedOpt {
+	return func(l *FingersCrossedLogging) {
+		l.level = level
+	}
+}
+
+// 配置缓存的最大条数及字节数，小于等于0时不限制
+func SetFingersCrossedLimit(maxEntries, maxBytes int) FingersCrossedOpt {
+	return func(l *FingersCrossedLogging) {
+		l.maxEntries = maxEntries
+		l.maxBytes = maxBytes
+	}
+}
+
+// 是否已触发输出（线程安全）
+func (l *FingersCrossedLogging) Triggered() bool {
+	if l == nil {
+		return false
+	}
+	return atomic.LoadInt32(&l.triggered) == 1
+}
+
+// 获得当前缓存因超出缓存限制被丢弃的日志数量，输出、丢弃缓存及重置时清零（线程安全）
+func (l *FingersCrossedLogging) Dropped() uint64 {
+	if l == nil {
+		return 0
+	}
+	return atomic.LoadUint64(&l.dropped)
+}
+
+// 丢弃缓存的日志，用于工作单元成功结束（线程安全）
+func (l *FingersCrossedLogging) Discard() {
+	if l == nil {
+		return
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.entries = nil
+	l.size = 0
+	atomic.StoreUint64(&l.dropped, 0)
+}
+
+// 输出缓存的日志，之后的日志直接输出（线程安全）
+func (l *FingersCrossedLogging) Flush() {
+	if l == nil {
+		return
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.flush()
+}
+
+// 丢弃缓存的日志并恢复缓存模式，用于复用于下一个工作单元（线程安全）
+func (l *FingersCrossedLogging) Reset() {
+	if l == nil {
+		return
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.entries = nil
+	l.size = 0
+	atomic.StoreUint64(&l.dropped, 0)
+	atomic.StoreInt32(&l.triggered, 0)
+}
+
+func (l *FingersCrossedLogging) flush() {
+	if len(l.entries) > 0 {
+		// 缓存的日志不受原Logging日志级别的限制
+		dropped := atomic.SwapUint64(&l.dropped, 0)
+		for i := range l.entries {
+			e := &l.entries[i]
+			if i == 0 && dropped > 0 {
+				kvs := e.keyValues.KeyValues.Clone()
+				addFields(kvs, []Field{Uint64(KeyBufferDropped, dropped)})
+				e.keyValues.KeyValues = kvs
+			}
+			replayEntry(l.logging, e.level, &e.keyValues, e.msg)
+		}
+		l.entries = nil
+		l.size = 0
+	}
+	atomic.StoreInt32(&l.triggered, 1)
+}
+
+// 判断是否直接输出，出现触发级别的日志时先输出缓存的日志
+func (l *FingersCrossedLogging) pass(level Level) bool {
+	if atomic.LoadInt32(&l.triggered) == 1 {
+		return true
+	}
+	if level <= l.trigger || level <= PANIC {
+		l.Flush()
+		return true
+	}
+	return false
+}
+
+// 缓存日志，返回false表示已触发输出，需直接输出
+func (l *FingersCrossedLogging) record(level Level, depth int, keyValues KeyValues, msg string) bool {
+	// keyValues可能在调用返回后放回池中（参见borrowsKeyValues），缓存时复制
+	if keyValues == nil {
+		keyValues = NewFields()
+	} else {
+		keyValues = keyValues.Clone()
+	}
+	e := bufferedEntry{
+		level: level,
+		keyValues: recordedKeyValues{
+			KeyValues: keyValues,
+			time:      time.Now(),
+		},
+		msg: msg,
+	}
+	frame, ok := recordCaller(l.logging, 2+depth)
+	e.keyValues.pc, e.keyValues.function, e.keyValues.file, e.keyValues.line, e.keyValues.ok = frame.PC, frame.Function, frame.File, frame.Line, ok
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if atomic.LoadInt32(&l.triggered) == 1 {
+		return false
+	}
+	l.entries = append(l.entries, e)
+	l.size += len(msg)
+	for len(l.entries) > 1 && ((l.maxEntries > 0 && len(l.entries) > l.maxEntries) || (l.maxBytes > 0 && l.size > l.maxBytes)) {
+		l.size -= len(l.entries[0].msg)
+		l.entries[0] = bufferedEntry{}
+		l.entries = l.entries[1:]
+		atomic.AddUint64(&l.dropped, 1)
+	}
+	return true
+}
+
+func (l *FingersCrossedLogging) Logf(level Level, depth int, keyValues KeyValues, format string, args ...interface{}) {
+	if l.pass(level) {
+		l.logging.Logf(level, depth+1, keyValues, format, args...)
+		return
+	}
+	if level > l.level {
+		return
+	}
+	// 与Logging.Logf相同，末尾补充换行
+	if length := len(format); length > 0 && format[length-1] != '\n' {
+		format = format + "\n"
+	}
+	if !l.record(level, depth, keyValues, fmt.Sprintf(format, args...)) {
+		l.logging.Logf(level, depth+1, keyValues, format, args...)
+	}
+}
+
+func (l *FingersCrossedLogging) Log(level Level, depth int, keyValues KeyValues, args ...interface{}) {
+	if l.pass(level) {
+		l.logging.Log(level, depth+1, keyValues, args...)
+		return
+	}
+	if level > l.level {
+		return
+	}
+	if !l.record(level, depth, keyValues, fmt.Sprint(args...)) {
+		l.logging.Log(level, depth+1, keyValues, args...)
+	}
+}
+
+func (l *FingersCrossedLogging) Logln(level Level, depth int, keyValues KeyValues, args ...interface{}) {
+	if l.pass(level) {
+		l.logging.Logln(level, depth+1, keyValues, args...)
+		return
+	}
+	if level > l.level {
+		return
+	}
+	if !l.record(level, depth, keyValues, fmt.Sprintln(args...)) {
+		l.logging.Logln(level, depth+1, keyValues, args...)
+	}
+}
+
+func (l *FingersCrossedLogging) borrowsKeyValues() bool {
+	return borrowsKeyValues(l.logging)
+}
+
+func (l *FingersCrossedLogging) callerFrame(skip int) (runtime.Frame, bool) {
+	return recordCaller(l.logging, skip+1)
+}
+
+func (l *FingersCrossedLogging) logEntry(level Level, keyValues KeyValues, msg string) {
+	replayEntry(l.logging, level, keyValues, msg)
+}
+
+func (l *FingersCrossedLogging) SetFormatter(f Formatter) {
+	l.logging.SetFormatter(f)
+}
+
+func (l *FingersCrossedLogging) GetFormatter() Formatter {
+	return l.logging.GetFormatter()
+}
+
+func (l *FingersCrossedLogging) SetFormatterBySeverity(severityLevel Level, f Formatter) {
+	l.logging.SetFormatterBySeverity(severityLevel, f)
+}
+
+func (l *FingersCrossedLogging) GetFormatterBySeverity(severityLevel Level) Formatter {
+	return l.logging.GetFormatterBySeverity(severityLevel)
+}
+
+func (l *FingersCrossedLogging) SetSeverityLevel(severityLevel Level) {
+	l.logging.SetSeverityLevel(severityLevel)
+}
+
+func (l *FingersCrossedLogging) GetSeverityLevel() Level {
+	return l.logging.GetSeverityLevel()
+}
+
+func (l *FingersCrossedLogging) SetCallerFlag(flag int) {
+	l.logging.SetCallerFlag(flag)
+}
+
+func (l *FingersCrossedLogging) SetColorFlag(flag int) {
+	l.logging.SetColorFlag(flag)
+}
+
+// 缓存的级别或logging输出的级别
+func (l *FingersCrossedLogging) IsEnabled(severityLevel Level) bool {
+	return (severityLevel <= l.level && !l.Triggered()) || l.logging.IsEnabled(severityLevel)
+}
+
+func (l *FingersCrossedLogging) SetSeverityLevelByName(name string, severityLevel Level) {
+	l.logging.SetSeverityLevelByName(name, severityLevel)
+}
+
+func (l *FingersCrossedLogging) SetSeverityLevels(levels map[string]Level) {
+	l.logging.SetSeverityLevels(levels)
+}
+
+func (l *FingersCrossedLogging) GetSeverityLevels() map[string]Level {
+	return l.logging.GetSeverityLevels()
+}
+
+// 缓存的级别或logging输出的级别
+func (l *FingersCrossedLogging) IsEnabledByName(name string, severityLevel Level) bool {
+	return (severityLevel <= l.level && !l.Triggered()) || l.logging.IsEnabledByName(name, severityLevel)
+}
+
+func (l *FingersCrossedLogging) SetOutput(w io.Writer) {
+	l.logging.SetOutput(w)
+}
+
+func (l *FingersCrossedLogging) SetOutputBySeverity(severityLevel Level, w io.Writer) {
+	l.logging.SetOutputBySeverity(severityLevel, w)
+}
+
+func (l *FingersCrossedLogging) GetOutputBySeverity(severity Level) io.Writer {
+	return l.logging.GetOutputBySeverity(severity)
+}
+
+func (l *FingersCrossedLogging) AddAppender(appender Appender) {
+	l.logging.AddAppender(appender)
+}
+
+func (l *FingersCrossedLogging) RemoveAppender(appender Appender) {
+	l.logging.RemoveAppender(appender)
+}
+
+func (l *FingersCrossedLogging) GetAppenders() []Appender {
+	return l.logging.GetAppenders()
+}
+
+func (l *FingersCrossedLogging) AddFilter(filter Filter) {
+	l.logging.AddFilter(filter)
+}
+
+func (l *FingersCrossedLogging) GetErrorStats() ErrorStats {
+	return l.logging.GetErrorStats()
+}
+
+func (l *FingersCrossedLogging) Config() LoggingConfig {
+	return l.logging.Config()
+}
+
+// 复制配置，不复制缓存的日志
+func (l *FingersCrossedLogging) Clone() Logging {
+	return &FingersCrossedLogging{
+		logging:    l.logging.Clone(),
+		trigger:    l.trigger,
+		level:      l.level,
+		maxEntries: l.maxEntries,
+		maxBytes:   l.maxBytes,
+	}
+}
@@ -0,0 +1,318 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package xlog
+
+import (
+	"io"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// 采样丢弃日志数量的Key
+	KeySampledDropped = "LogDropped"
+
+	samplingCounterSize = 4096
+)
+
+// 日志采样配置
+type SamplingConfig struct {
+	// 每个周期内相同级别及内容的日志，前Initial条全部输出
+	Initial uint64
+	// 超出Initial后每Thereafter条输出1条，为0时该周期内不再输出
+	Thereafter uint64
+	// 采样周期，小于等于0时不采样
+	Tick time.Duration
+}
+
+// 默认的采样配置：每秒相同级别及内容的日志输出前100条，之后每100条输出1条
+var DefaultSamplingConfig = SamplingConfig{
+	Initial:    100,
+	Thereafter: 100,
+	Tick:       time.Second,
+}
+
+type SamplingOpt func(l *SamplingLogging)
+
+type samplingCounter struct {
+	resetAt int64
+	counter uint64
+	dropped uint64
+}
+
+// 采样输出日志的Logging，使用NewSamplingLogging创建
+type SamplingLogging struct {
+	logging  Logging
+	config   SamplingConfig
+	levels   map[Level]SamplingConfig
+	counters *[samplingCounterSize]samplingCounter
+	dropped  uint64
+}
+
+// 采样输出日志的Logging，相同级别及内容的日志在周期内超出配置数量后将被丢弃，
+// 内容为Logf的format参数，Log及Logln的第一个参数为字符串时为该参数，否则为调用位置（不格式化日志参数），
+// 被丢弃的数量会以KeySampledDropped附加在该日志下一次输出的信息中，PANIC及FATAL级别的日志不采样
+// Param： logging实际输出的Logging，opts采样配置，默认使用DefaultSamplingConfig
+func NewSamplingLogging(logging Logging, opts ...SamplingOpt) *SamplingLogging {
+	ret := &SamplingLogging{
+		logging:  logging,
+		config:   DefaultSamplingConfig,
+		levels:   map[Level]SamplingConfig{},
+		counters: &[samplingCounterSize]samplingCounter{},
+	}
+	for _, v := range opts {
+		v(ret)
+	}
+	return ret
+}
+
+// 配置所有级别默认的采样配置
+func SetSamplingConfig(conf SamplingConfig) SamplingOpt {
+	return func(l *SamplingLogging) {
+		l.config = conf
+	}
+}
+
+// 配置对应日志级别的采样配置，Tick为0时该级别不采样
+func SetSamplingByLevel(level Level, conf SamplingConfig) SamplingOpt {
+	return func(l *SamplingLogging) {
+		l.levels[level] = conf
+	}
+}
+
+// 获得累计被丢弃的日志数量（线程安全）
+func (l *SamplingLogging) Dropped() uint64 {
+	return atomic.LoadUint64(&l.dropped)
+}
+
+func (l *SamplingLogging) Logf(level Level, depth int, keyValues KeyValues, format string, args ...interface{}) {
+	if !l.logging.IsEnabledByName(loggerName(keyValues), level) {
+		return
+	}
+	keyValues, ok := l.sample(level, samplingHash(level, format), keyValues)
+	if ok {
+		l.logging.Logf(level, depth+1, keyValues, format, args...)
+	}
+}
+
+func (l *SamplingLogging) Log(level Level, depth int, keyValues KeyValues, args ...interface{}) {
+	if !l.logging.IsEnabledByName(loggerName(keyValues), level) {
+		return
+	}
+	keyValues, ok := l.sample(level, samplingArgsHash(level, depth, args), keyValues)
+	if ok {
+		l.logging.Log(level, depth+1, keyValues, args...)
+	}
+}
+
+func (l *SamplingLogging) Logln(level Level, depth int, keyValues KeyValues, args ...interface{}) {
+	if !l.logging.IsEnabledByName(loggerName(keyValues), level) {
+		return
+	}
+	keyValues, ok := l.sample(level, samplingArgsHash(level, depth, args), keyValues)
+	if ok {
+		l.logging.Logln(level, depth+1, keyValues, args...)
+	}
+}
+
+func (l *SamplingLogging) borrowsKeyValues() bool {
+	return borrowsKeyValues(l.logging)
+}
+
+func (l *SamplingLogging) callerFrame(skip int) (runtime.Frame, bool) {
+	return recordCaller(l.logging, skip+1)
+}
+
+func (l *SamplingLogging) logEntry(level Level, keyValues KeyValues, msg string) {
+	replayEntry(l.logging, level, keyValues, msg)
+}
+
+func (l *SamplingLogging) sample(level Level, hash uint32, keyValues KeyValues) (KeyValues, bool) {
+	// PANIC及FATAL需要触发panic或退出，不采样
+	if level <= PANIC {
+		return keyValues, true
+	}
+	conf, ok := l.levels[level]
+	if !ok {
+		conf = l.config
+	}
+	if conf.Tick <= 0 {
+		return keyValues, true
+	}
+
+	c := &l.counters[hash%samplingCounterSize]
+	n := c.incCheckReset(time.Now(), conf.Tick)
+	if n > conf.Initial && (conf.Thereafter == 0 || (n-conf.Initial)%conf.Thereafter != 0) {
+		atomic.AddUint64(&c.dropped, 1)
+		atomic.AddUint64(&l.dropped, 1)
+		return keyValues, false
+	}
+
+	dropped := atomic.SwapUint64(&c.dropped, 0)
+	if dropped > 0 {
+		if keyValues == nil {
+			keyValues = NewFields()
+		} else {
+			keyValues = keyValues.Clone()
+		}
+		addFields(keyValues, []Field{Uint64(KeySampledDropped, dropped)})
+	}
+	return keyValues, true
+}
+
+func (c *samplingCounter) incCheckReset(t time.Time, tick time.Duration) uint64 {
+	tn := t.UnixNano()
+	resetAt := atomic.LoadInt64(&c.resetAt)
+	if resetAt > tn {
+		return atomic.AddUint64(&c.counter, 1)
+	}
+
+	atomic.StoreUint64(&c.counter, 1)
+	if !atomic.CompareAndSwapInt64(&c.resetAt, resetAt, tn+tick.Nanoseconds()) {
+		// 其他goroutine已重置
+		return atomic.AddUint64(&c.counter, 1)
+	}
+	return 1
+}
+
+// FNV-1a的参数
+const (
+	offset32 = 2166136261
+	prime32  = 16777619
+)
+
+// FNV-1a
+func samplingHash(level Level, msg string) uint32 {
+	h := uint32(offset32)
+	h ^= uint32(level)
+	h *= prime32
+	for i := 0; i < len(msg); i++ {
+		h ^= uint32(msg[i])
+		h *= prime32
+	}
+	return h
+}
+
+// 第一个参数为字符串时使用该参数，否则使用调用位置，避免格式化参数（及对延迟求值的参数求值）
+func samplingArgsHash(level Level, depth int, args []interface{}) uint32 {
+	if len(args) > 0 {
+		if s, ok := args[0].(string); ok {
+			return samplingHash(level, s)
+		}
+	}
+	var pcs [1]uintptr
+	runtime.Callers(3+depth, pcs[:])
+	h := uint32(offset32)
+	h ^= uint32(level)
+	h *= prime32
+	for pc := uint64(pcs[0]); pc != 0; pc >>= 8 {
+		h ^= uint32(pc & 0xff)
+		h *= prime32
+	}
+	return h
+}
+
+func (l *SamplingLogging) SetFormatter(f Formatter) {
+	l.logging.SetFormatter(f)
+}
+
+func (l *SamplingLogging) GetFormatter() Formatter {
+	return l.logging.GetFormatter()
+}
+
+func (l *SamplingLogging) SetFormatterBySeverity(severityLevel Level, f Formatter) {
+	l.logging.SetFormatterBySeverity(severityLevel, f)
+}
+
+func (l *SamplingLogging) GetFormatterBySeverity(severityLevel Level) Formatter {
+	return l.logging.GetFormatterBySeverity(severityLevel)
+}
+
+func (l *SamplingLogging) SetSeverityLevel(severityLevel Level) {
+	l.logging.SetSeverityLevel(severityLevel)
+}
+
+func (l *SamplingLogging) GetSeverityLevel() Level {
+	return l.logging.GetSeverityLevel()
+}
+
+func (l *SamplingLogging) SetCallerFlag(flag int) {
+	l.logging.SetCallerFlag(flag)
+}
+
+func (l *SamplingLogging) SetColorFlag(flag int) {
+	l.logging.SetColorFlag(flag)
+}
+
+func (l *SamplingLogging) IsEnabled(severityLevel Level) bool {
+	return l.logging.IsEnabled(severityLevel)
+}
+
+func (l *SamplingLogging) SetSeverityLevelByName(name string, severityLevel Level) {
+	l.logging.SetSeverityLevelByName(name, severityLevel)
+}
+
+func (l *SamplingLogging) SetSeverityLevels(levels map[string]Level) {
+	l.logging.SetSeverityLevels(levels)
+}
+
+func (l *SamplingLogging) GetSeverityLevels() map[string]Level {
+	return l.logging.GetSeverityLevels()
+}
+
+func (l *SamplingLogging) IsEnabledByName(name string, severityLevel Level) bool {
+	return l.logging.IsEnabledByName(name, severityLevel)
+}
+
+func (l *SamplingLogging) SetOutput(w io.Writer) {
+	l.logging.SetOutput(w)
+}
+
+func (l *SamplingLogging) SetOutputBySeverity(severityLevel Level, w io.Writer) {
+	l.logging.SetOutputBySeverity(severityLevel, w)
+}
+
+func (l *SamplingLogging) GetOutputBySeverity(severity Level) io.Writer {
+	return l.logging.GetOutputBySeverity(severity)
+}
+
+func (l *SamplingLogging) AddAppender(appender Appender) {
+	l.logging.AddAppender(appender)
+}
+
+func (l *SamplingLogging) RemoveAppender(appender Appender) {
+	l.logging.RemoveAppender(appender)
+}
+
+func (l *SamplingLogging) GetAppenders() []Appender {
+	return l.logging.GetAppenders()
+}
+
+func (l *SamplingLogging) AddFilter(filter Filter) {
+	l.logging.AddFilter(filter)
+}
+
+func (l *SamplingLogging) GetErrorStats() ErrorStats {
+	return l.logging.GetErrorStats()
+}
+
+func (l *SamplingLogging) Config() LoggingConfig {
+	return l.logging.Config()
+}
+
+func (l *SamplingLogging) Clone() Logging {
+	levels := make(map[Level]SamplingConfig, len(l.levels))
+	for k, v := range l.levels {
+		levels[k] = v
+	}
+	return &SamplingLogging{
+		logging:  l.logging.Clone(),
+		config:   l.config,
+		levels:   levels,
+		counters: &[samplingCounterSize]samplingCounter{},
+	}
+}
@@ -5,7 +5,10 @@
 
 package xlog
 
-import "io"
+import (
+	"io"
+	"runtime"
+)
 
 type LevelHook func(Level) Level
 
@@ -33,18 +36,70 @@ func (l *hookLevelLogging) Logln(level Level, depth int, keyValues KeyValues, ar
 	l.logging.Logln(l.hook(level), depth+1, keyValues, args...)
 }
 
+func (l *hookLevelLogging) borrowsKeyValues() bool {
+	return borrowsKeyValues(l.logging)
+}
+
+func (l *hookLevelLogging) callerFrame(skip int) (runtime.Frame, bool) {
+	return recordCaller(l.logging, skip+1)
+}
+
+func (l *hookLevelLogging) logEntry(level Level, keyValues KeyValues, msg string) {
+	replayEntry(l.logging, l.hook(level), keyValues, msg)
+}
+
 func (l *hookLevelLogging) SetFormatter(f Formatter) {
 	l.logging.SetFormatter(f)
 }
 
+func (l *hookLevelLogging) GetFormatter() Formatter {
+	return l.logging.GetFormatter()
+}
+
+func (l *hookLevelLogging) SetFormatterBySeverity(severityLevel Level, f Formatter) {
+	l.logging.SetFormatterBySeverity(severityLevel, f)
+}
+
+func (l *hookLevelLogging) GetFormatterBySeverity(severityLevel Level) Formatter {
+	return l.logging.GetFormatterBySeverity(severityLevel)
+}
+
 func (l *hookLevelLogging) SetSeverityLevel(severityLevel Level) {
 	l.logging.SetSeverityLevel(severityLevel)
 }
 
+func (l *hookLevelLogging) GetSeverityLevel() Level {
+	return l.logging.GetSeverityLevel()
+}
+
+func (l *hookLevelLogging) SetCallerFlag(flag int) {
+	l.logging.SetCallerFlag(flag)
+}
+
+func (l *hookLevelLogging) SetColorFlag(flag int) {
+	l.logging.SetColorFlag(flag)
+}
+
 func (l *hookLevelLogging) IsEnabled(severityLevel Level) bool {
 	return l.logging.IsEnabled(severityLevel)
 }
 
+func (l *hookLevelLogging) SetSeverityLevelByName(name string, severityLevel Level) {
+	l.logging.SetSeverityLevelByName(name, severityLevel)
+}
+
+func (l *hookLevelLogging) SetSeverityLevels(levels map[string]Level) {
+	l.logging.SetSeverityLevels(levels)
+}
+
+func (l *hookLevelLogging) GetSeverityLevels() map[string]Level {
+	return l.logging.GetSeverityLevels()
+}
+
+func (l *hookLevelLogging) IsEnabledByName(name string, severityLevel Level) bool {
+	return l.logging.IsEnabledByName(name, severityLevel)
+}
+
 func (l *hookLevelLogging) SetOutput(w io.Writer) {
 	l.logging.SetOutput(w)
 }
@@ -57,6 +112,30 @@ func (l *hookLevelLogging) GetOutputBySeverity(severity Level) io.Writer {
 	return l.logging.GetOutputBySeverity(severity)
 }
 
+func (l *hookLevelLogging) AddAppender(appender Appender) {
+	l.logging.AddAppender(appender)
+}
+
+func (l *hookLevelLogging) RemoveAppender(appender Appender) {
+	l.logging.RemoveAppender(appender)
+}
+
+func (l *hookLevelLogging) GetAppenders() []Appender {
+	return l.logging.GetAppenders()
+}
+
+func (l *hookLevelLogging) AddFilter(filter Filter) {
+	l.logging.AddFilter(filter)
+}
+
+func (l *hookLevelLogging) GetErrorStats() ErrorStats {
+	return l.logging.GetErrorStats()
+}
+
+func (l *hookLevelLogging) Config() LoggingConfig {
+	return l.logging.Config()
+}
+
 func (l *hookLevelLogging) Clone() Logging {
 	return &hookLevelLogging{
 		logging: l.logging.Clone(),
@@ -0,0 +1,168 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package xlog
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+const (
+	// 超出该容量的缓存不放回池中，避免个别超大日志长期占用内存
+	maxPooledBufferSize = 64 << 10
+	maxPooledFieldsSize = 64
+
+	callerCacheSize = 256
+)
+
+// 格式化日志使用的字节缓存，通过getBuffer从池中获得，使用后通过putBuffer放回
+type logBuffer struct {
+	b []byte
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.b = append(b.b, p...)
+	return len(p), nil
+}
+
+func (b *logBuffer) WriteString(s string) (int, error) {
+	b.b = append(b.b, s...)
+	return len(s), nil
+}
+
+var (
+	bufferPool = sync.Pool{New: func() interface{} {
+		return &logBuffer{b: make([]byte, 0, 1024)}
+	}}
+	fieldsPool = sync.Pool{New: func() interface{} {
+		fs := make(Fields, 0, 16)
+		return &fs
+	}}
+	entryPool = sync.Pool{New: func() interface{} {
+		return &Entry{}
+	}}
+)
+
+func getBuffer() *logBuffer {
+	return bufferPool.Get().(*logBuffer)
+}
+
+func putBuffer(b *logBuffer) {
+	if b == nil || cap(b.b) > maxPooledBufferSize {
+		return
+	}
+	b.b = b.b[:0]
+	bufferPool.Put(b)
+}
+
+// 内置Logging及包装Logging的实现，返回true时不在Logf、Log、Logln返回后使用keyValues（需要时已复制），
+// 调用时合并的附加信息可以放回池中
+type keyValuesBorrower interface {
+	borrowsKeyValues() bool
+}
+
+// 其他Logging实现可能在调用返回后使用keyValues，合并的附加信息不放回池中
+func borrowsKeyValues(logging Logging) bool {
+	b, ok := logging.(keyValuesBorrower)
+	return ok && b.borrowsKeyValues()
+}
+
+func getFields() *Fields {
+	return fieldsPool.Get().(*Fields)
+}
+
+func putFields(fs *Fields) {
+	if cap(*fs) > maxPooledFieldsSize {
+		return
+	}
+	// 释放对值的引用
+	for i := range *fs {
+		(*fs)[i] = Field{}
+	}
+	*fs = (*fs)[:0]
+	fieldsPool.Put(fs)
+}
+
+func getEntry() *Entry {
+	return entryPool.Get().(*Entry)
+}
+
+func putEntry(e *Entry) {
+	*e = Entry{}
+	entryPool.Put(e)
+}
+
+const (
+	printfKind = iota
+	printKind
+	printlnKind
+)
+
+// 按Logf（补充末尾换行）、Log、Logln的规则格式化日志内容并写入缓存
+func appendMessage(buf *logBuffer, kind int, format string, args []interface{}) {
+	switch kind {
+	case printfKind:
+		fmt.Fprintf(buf, format, args...)
+		if length := len(format); length > 0 && format[length-1] != '\n' {
+			buf.b = append(buf.b, '\n')
+		}
+	case printKind:
+		fmt.Fprint(buf, args...)
+	default:
+		fmt.Fprintln(buf, args...)
+	}
+}
+
+// 格式化日志内容，用于需要保存日志内容的场景（如过滤器）
+func sprintMessage(kind int, format string, args []interface{}) string {
+	buf := getBuffer()
+	appendMessage(buf, kind, format, args)
+	ret := string(buf.b)
+	putBuffer(buf)
+	return ret
+}
+
+// 不复制地将缓存转换为字符串，返回值仅在缓存放回池之前有效
+func bytesToString(b []byte) string {
+	return *(*string)(unsafe.Pointer(&b))
+}
+
+// 复制字符串，用于保存由bytesToString转换的字符串
+func cloneString(s string) string {
+	if s == "" {
+		return ""
+	}
+	b := make([]byte, len(s))
+	copy(b, s)
+	return bytesToString(b)
+}
+
+type callerCacheEntry struct {
+	pc     uintptr
+	flag   int
+	caller string
+}
+
+// 按调用位置（pc）缓存格式化后的调用者信息，冲突时直接覆盖
+// 调用者的输出标志可以修改，因此同时比较格式化时使用的标志
+type callerCache [callerCacheSize]atomic.Value
+
+func (c *callerCache) slot(pc uintptr) *atomic.Value {
+	return &c[(pc^(pc>>8))&(callerCacheSize-1)]
+}
+
+func (c *callerCache) get(pc uintptr, flag int) (string, bool) {
+	if e, ok := c.slot(pc).Load().(*callerCacheEntry); ok && e.pc == pc && e.flag == flag {
+		return e.caller, true
+	}
+	return "", false
+}
+
+func (c *callerCache) put(pc uintptr, flag int, caller string) {
+	c.slot(pc).Store(&callerCacheEntry{pc: pc, flag: flag, caller: caller})
+}
@@ -0,0 +1,119 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package xlog
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+)
+
+const (
+	// 主机名Key，参见SetHostname
+	KeyHostname = "LogHost"
+	// 进程ID Key，参见SetPid
+	KeyPid = "LogPid"
+	// 可执行文件名称Key，参见SetAppName
+	KeyApp = "LogApp"
+	// 构建版本Key，参见SetBuildVersion
+	KeyVersion = "LogVersion"
+	// 协程ID Key，参见SetGoroutineID
+	KeyGoroutine = "LogGoroutine"
+)
+
+// 配置每条日志附加的静态信息，在创建Logging时确定，Logger的附加信息相同时覆盖静态信息
+func SetStaticFields(fields ...Field) func(*logging) {
+	return func(logging *logging) {
+		staticFields := make(Fields, len(logging.staticFields), len(logging.staticFields)+len(fields))
+		copy(staticFields, logging.staticFields)
+		staticFields.AddFields(fields...)
+		logging.staticFields = staticFields
+	}
+}
+
+// 附加主机名，key为空时使用KeyHostname
+func SetHostname(key string) func(*logging) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return SetStaticFields(String(keyOrDefault(key, KeyHostname), hostname))
+}
+
+// 附加进程ID，key为空时使用KeyPid
+func SetPid(key string) func(*logging) {
+	return SetStaticFields(Int(keyOrDefault(key, KeyPid), os.Getpid()))
+}
+
+// 附加可执行文件名称，key为空时使用KeyApp
+func SetAppName(key string) func(*logging) {
+	name, err := os.Executable()
+	if err != nil {
+		name = os.Args[0]
+	}
+	return SetStaticFields(String(keyOrDefault(key, KeyApp), filepath.Base(name)))
+}
+
+// 附加构建版本（debug.ReadBuildInfo获得的主模块版本），key为空时使用KeyVersion
+func SetBuildVersion(key string) func(*logging) {
+	version := "unknown"
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		version = info.Main.Version
+	}
+	return SetStaticFields(String(keyOrDefault(key, KeyVersion), version))
+}
+
+// 每条日志附加输出日志的协程ID，key为空时使用KeyGoroutine。注意获得协程ID有一定开销
+func SetGoroutineID(key string) func(*logging) {
+	return func(logging *logging) {
+		logging.goroutineKey = keyOrDefault(key, KeyGoroutine)
+	}
+}
+
+func keyOrDefault(key, defaultKey string) string {
+	if key == "" {
+		return defaultKey
+	}
+	return key
+}
+
+var goroutinePrefix = []byte("goroutine ")
+
+// 获得当前协程ID，解析runtime.Stack的首行："goroutine 18 [running]:"
+func goroutineID() int64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, goroutinePrefix)
+	if i := bytes.IndexByte(b, ' '); i > 0 {
+		b = b[:i]
+	}
+	id, err := strconv.ParseInt(string(b), 10, 64)
+	if err != nil {
+		return -1
+	}
+	return id
+}
+
+// 附加静态信息及协程ID，未配置时返回参数本身
+func (l *logging) processFields(keyValues KeyValues) KeyValues {
+	if len(l.staticFields) == 0 && l.goroutineKey == "" {
+		return keyValues
+	}
+	size := len(l.staticFields) + 1
+	if keyValues != nil {
+		size += keyValues.Len()
+	}
+	ret := make(Fields, len(l.staticFields), size)
+	copy(ret, l.staticFields)
+	if l.goroutineKey != "" {
+		ret.AddFields(Int64(l.goroutineKey, goroutineID()))
+	}
+	MergeKeyValues(&ret, keyValues)
+	return &ret
+}
@@ -0,0 +1,81 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package xlog
+
+import (
+	"fmt"
+)
+
+// panic值，参见Logger.Recover
+const KeyPanic = "LogPanic"
+
+// 捕获panic后的处理方式
+type RecoverMode int
+
+const (
+	// 记录日志后忽略panic
+	RecoverSwallow RecoverMode = iota
+	// 记录日志后使用原panic值重新panic
+	RecoverRepanic
+	// 使用FATAL级别记录日志，并通过Logging配置的ExitFunc退出程序
+	RecoverExit
+)
+
+type RecoverOpt func(c *recoverConfig)
+
+type recoverConfig struct {
+	level Level
+	mode  RecoverMode
+}
+
+// 配置记录panic的日志级别，默认为ERROR，RecoverExit时总是使用FATAL。
+// 注意使用PANIC级别时Logging会再次panic
+func SetRecoverLevel(severityLevel Level) func(*recoverConfig) {
+	return func(c *recoverConfig) {
+		c.level = severityLevel
+	}
+}
+
+// 配置捕获panic后的处理方式，默认为RecoverSwallow
+func SetRecoverMode(mode RecoverMode) func(*recoverConfig) {
+	return func(c *recoverConfig) {
+		c.mode = mode
+	}
+}
+
+// 记录panic值（KeyPanic）及调用栈（KeyStack），并按配置的方式处理
+func handlePanic(logFields func(level Level, msg string, fields []Field), v interface{}, opts []RecoverOpt) {
+	conf := recoverConfig{
+		level: ERROR,
+		mode:  RecoverSwallow,
+	}
+	for _, opt := range opts {
+		opt(&conf)
+	}
+	// 跳过handlePanic、Recover，从runtime.gopanic开始
+	stack := CaptureStackTrace(2)
+	level := conf.level
+	if conf.mode == RecoverExit {
+		level = FATAL
+	}
+	logFields(level, fmt.Sprintf("panic: %v", v), []Field{Any(KeyPanic, v), Any(KeyStack, stack)})
+	if conf.mode == RecoverRepanic {
+		panic(v)
+	}
+}
+
+// 启动协程执行f，使用默认的Logger记录f中的panic，参见Logger.Recover
+func Go(f func(), opts ...RecoverOpt) {
+	GoWith(GetLogger(), f, opts...)
+}
+
+// 启动协程执行f，使用参数Logger记录f中的panic，参见Logger.Recover
+func GoWith(logger Logger, f func(), opts ...RecoverOpt) {
+	go func() {
+		defer logger.Recover(opts...)
+		f()
+	}()
+}
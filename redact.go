@@ -0,0 +1,191 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package xlog
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// 常见的敏感信息key名称，可配合SetRedactKeys使用
+var SensitiveKeys = []string{"password", "passwd", "secret", "token", "authorization", "cookie"}
+
+// 实现该接口的值在输出时使用Redact的返回值代替，用于自定义类型的脱敏
+type Redactor interface {
+	Redact() interface{}
+}
+
+// 脱敏函数，参数为原始值的字符串形式，返回脱敏后的字符串
+type Masker func(s string) string
+
+// 全部遮盖，不暴露原始值的长度
+func MaskFull() Masker {
+	return func(s string) string {
+		return "******"
+	}
+}
+
+// 保留最后n个字符，其他字符使用'*'遮盖，长度不超过n时全部遮盖
+func MaskKeepLast(n int) Masker {
+	return func(s string) string {
+		size := utf8.RuneCountInString(s)
+		if size <= n {
+			return strings.Repeat("*", size)
+		}
+		runes := []rune(s)
+		return strings.Repeat("*", size-n) + string(runes[size-n:])
+	}
+}
+
+// 使用key计算HMAC-SHA256，相同的原始值得到相同的结果，可用于关联日志而不暴露原始值
+func MaskHash(key []byte) Masker {
+	return func(s string) string {
+		h := hmac.New(sha256.New, key)
+		h.Write([]byte(s))
+		return "hmac:" + hex.EncodeToString(h.Sum(nil)[:8])
+	}
+}
+
+type redactPattern struct {
+	re     *regexp.Regexp
+	masker Masker
+}
+
+type RedactionOpt func(r *Redaction)
+
+// 日志脱敏规则：按key名称（不区分大小写）、按正则表达式匹配字符串值及实现了Redactor的值进行脱敏。
+// 注意只匹配附加信息的顶层key，不处理嵌套的key（如map、结构体的字段），
+// 正则表达式只作用于日志内容、字符串、error的错误信息及字符串数组（如错误链KeyErrorCauses），不作用于其他类型的值
+type Redaction struct {
+	keys     map[string]Masker
+	patterns []redactPattern
+}
+
+// 创建脱敏规则，通过SetRedaction配置到Logging，实现了Redactor的值总是会被脱敏
+func NewRedaction(opts ...RedactionOpt) *Redaction {
+	ret := &Redaction{
+		keys: map[string]Masker{},
+	}
+	for _, v := range opts {
+		v(ret)
+	}
+	return ret
+}
+
+// 配置按key名称（不区分大小写）脱敏，如：SetRedactKeys(MaskFull(), SensitiveKeys...)
+func SetRedactKeys(masker Masker, keys ...string) func(*Redaction) {
+	return func(r *Redaction) {
+		for _, k := range keys {
+			r.keys[strings.ToLower(k)] = masker
+		}
+	}
+}
+
+// 配置对字符串值（包括日志内容、error的错误信息）中匹配正则表达式的部分脱敏，如银行卡号、邮箱
+func SetRedactPattern(re *regexp.Regexp, masker Masker) func(*Redaction) {
+	return func(r *Redaction) {
+		r.patterns = append(r.patterns, redactPattern{re: re, masker: masker})
+	}
+}
+
+// 对KeyValues脱敏，不修改参数，有值被脱敏时返回新的KeyValues
+func (r *Redaction) Redact(keyValues KeyValues) KeyValues {
+	if keyValues == nil || keyValues.Len() == 0 {
+		return keyValues
+	}
+	if fs, ok := keyValues.(*Fields); ok {
+		var ret Fields
+		for i, f := range *fs {
+			nf, changed := r.redactField(f)
+			if !changed {
+				continue
+			}
+			if ret == nil {
+				ret = make(Fields, len(*fs))
+				copy(ret, *fs)
+			}
+			ret[i] = nf
+		}
+		if ret == nil {
+			return keyValues
+		}
+		// 仅在有修改时分配，避免ret逃逸到堆
+		out := ret
+		return &out
+	}
+
+	changed := false
+	ret := make(Fields, 0, keyValues.Len())
+	for _, k := range keyValues.Keys() {
+		nf, ok := r.redactField(Any(k, keyValues.Get(k)))
+		changed = changed || ok
+		ret = append(ret, nf)
+	}
+	if !changed {
+		return keyValues
+	}
+	return &ret
+}
+
+// 对字符串中匹配正则表达式的部分脱敏
+func (r *Redaction) RedactString(s string) string {
+	for _, p := range r.patterns {
+		s = p.re.ReplaceAllStringFunc(s, p.masker)
+	}
+	return s
+}
+
+func (r *Redaction) redactField(f Field) (Field, bool) {
+	if masker, ok := r.keys[strings.ToLower(f.Key)]; ok {
+		if f.Type == StringType {
+			return String(f.Key, masker(f.String)), true
+		}
+		return String(f.Key, masker(fmt.Sprint(f.Value()))), true
+	}
+	if rd, ok := f.Interface.(Redactor); ok {
+		return Any(f.Key, rd.Redact()), true
+	}
+	if len(r.patterns) == 0 {
+		return f, false
+	}
+	switch f.Type {
+	case StringType:
+		s := r.RedactString(f.String)
+		if s != f.String {
+			return String(f.Key, s), true
+		}
+	case ErrorType:
+		msg := f.Interface.(error).Error()
+		if s := r.RedactString(msg); s != msg {
+			return NamedErr(f.Key, errors.New(s)), true
+		}
+	case AnyType:
+		if ss, ok := f.Interface.([]string); ok {
+			var ret []string
+			for i, v := range ss {
+				s := r.RedactString(v)
+				if s == v {
+					continue
+				}
+				if ret == nil {
+					ret = make([]string, len(ss))
+					copy(ret, ss)
+				}
+				ret[i] = s
+			}
+			if ret != nil {
+				return Any(f.Key, ret), true
+			}
+		}
+	}
+	return f, false
+}
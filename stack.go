@@ -0,0 +1,119 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package xlog
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// 调用栈的最大深度
+var MaxStackDepth = 64
+
+// 调用栈帧
+type Frame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+func (f Frame) String() string {
+	return f.Function + "(" + shortFile(f.File) + ":" + strconv.Itoa(f.Line) + ")"
+}
+
+// 当前协程的调用栈，JsonFormatter输出为帧数组，TextFormatter输出为单行
+type StackTrace []Frame
+
+// 获得当前协程的调用栈，skip为0时从CaptureStackTrace的调用者开始
+func CaptureStackTrace(skip int) StackTrace {
+	pcs := make([]uintptr, MaxStackDepth)
+	n := runtime.Callers(skip+2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	ret := make(StackTrace, 0, n)
+	for {
+		frame, more := frames.Next()
+		ret = append(ret, Frame{
+			Function: frame.Function,
+			File:     frame.File,
+			Line:     frame.Line,
+		})
+		if !more {
+			break
+		}
+	}
+	return ret
+}
+
+// 单行输出，以" < "分隔，如：main.f(main.go:10) < main.main(main.go:20)
+func (s StackTrace) String() string {
+	buf := strings.Builder{}
+	for i, f := range s {
+		if i > 0 {
+			buf.WriteString(" < ")
+		}
+		buf.WriteString(f.String())
+	}
+	return buf.String()
+}
+
+// 多行输出，与panic输出的调用栈格式相同
+func (s StackTrace) lines() string {
+	buf := strings.Builder{}
+	for _, f := range s {
+		buf.WriteString(f.Function)
+		buf.WriteString("\n\t")
+		buf.WriteString(f.File)
+		buf.WriteByte(':')
+		buf.WriteString(strconv.Itoa(f.Line))
+		buf.WriteByte('\n')
+	}
+	return buf.String()
+}
+
+// 获得错误链（不包括err本身），按深度优先顺序展开errors.Unwrap及Unwrap() []error（如errors.Join）
+func ErrorChain(err error) []error {
+	var ret []error
+	var walk func(e error)
+	walk = func(e error) {
+		switch x := e.(type) {
+		case interface{ Unwrap() []error }:
+			for _, v := range x.Unwrap() {
+				if v != nil {
+					ret = append(ret, v)
+					walk(v)
+				}
+			}
+		default:
+			if v := errors.Unwrap(e); v != nil {
+				ret = append(ret, v)
+				walk(v)
+			}
+		}
+	}
+	if err != nil {
+		walk(err)
+	}
+	return ret
+}
+
+// error类型的附加信息的错误链Key后缀，参见SetExtractErrors
+const errorCausesSuffix = "Causes"
+
+// 错误链中各错误的类型及信息，格式为"类型: 错误信息"，如"*errors.errorString: EOF"
+func errorCauses(err error) []string {
+	chain := ErrorChain(err)
+	if len(chain) == 0 {
+		return nil
+	}
+	ret := make([]string, len(chain))
+	for i, v := range chain {
+		ret[i] = fmt.Sprintf("%T: %s", v, v.Error())
+	}
+	return ret
+}
@@ -0,0 +1,102 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/xfali/xlog"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestAppender(t *testing.T) {
+	console := &bytes.Buffer{}
+	file := &bytes.Buffer{}
+	logging := xlog.NewLogging()
+	logging.SetSeverityLevel(xlog.DEBUG)
+	logging.SetOutput(ioutil.Discard)
+	logging.AddAppender(xlog.NewAppender(console,
+		xlog.SetAppenderLevel(xlog.INFO),
+		xlog.SetAppenderColorFlag(xlog.AutoColor)))
+	logging.AddAppender(xlog.NewAppender(file,
+		xlog.SetAppenderFormatter(&xlog.JsonFormatter{}),
+		xlog.SetAppenderFilter(xlog.FilterFunc(func(entry *xlog.Entry) xlog.FilterResult {
+			if entry.Name == "chatty" {
+				return xlog.FilterDeny
+			}
+			return xlog.FilterNeutral
+		}))))
+	if len(logging.GetAppenders()) != 2 || len(logging.Clone().GetAppenders()) != 2 {
+		t.Fatal("expect 2 appenders")
+	}
+
+	fac := xlog.NewFactory(logging)
+	fac.GetLogger("test").Debugln("debug log")
+	fac.GetLogger("chatty").Warnln("chatty log")
+
+	if !strings.Contains(console.String(), "["+xlog.ForeYellow+"WARN") || !strings.Contains(console.String(), "chatty log") || strings.Contains(console.String(), "debug log") {
+		t.Fatal("console not match: ", console.String())
+	}
+	ret := map[string]interface{}{}
+	if err := json.Unmarshal(file.Bytes(), &ret); err != nil {
+		t.Fatal(err, file.String())
+	}
+	if ret[xlog.KeyName] != "test" || ret[xlog.KeySeverityLevel] != "DEBUG" || strings.Contains(file.String(), "chatty log") {
+		t.Fatal("file not match: ", file.String())
+	}
+}
+
+type keepAppender struct {
+	entries []*xlog.Entry
+}
+
+func (a *keepAppender) IsEnabled(severityLevel xlog.Level) bool {
+	return true
+}
+
+func (a *keepAppender) Append(entry *xlog.Entry) error {
+	a.entries = append(a.entries, entry.Clone())
+	return nil
+}
+
+func TestAppenderKeepEntry(t *testing.T) {
+	logging := xlog.NewLogging()
+	logging.SetOutput(ioutil.Discard)
+	a := &keepAppender{}
+	logging.AddAppender(a)
+	logger := xlog.NewFactory(logging).GetLogger("keep")
+	for i := 0; i < 3; i++ {
+		logger.InfoFields("entry "+strconv.Itoa(i), xlog.Int("i", i))
+	}
+	for i, e := range a.entries {
+		if e.Message() != "entry "+strconv.Itoa(i)+"\n" || e.KeyValues.Get("i") != int64(i) || e.Name != "keep" {
+			t.Fatal("cloned entry changed: ", i, e.Message(), e.KeyValues.GetAll())
+		}
+	}
+}
+
+func TestRemoveAppender(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logging := xlog.NewLogging()
+	logging.SetOutput(ioutil.Discard)
+	a1 := xlog.NewAppender(buf)
+	a2 := &keepAppender{}
+	logging.AddAppender(a1)
+	logging.AddAppender(a2)
+
+	logging.RemoveAppender(a1)
+	logging.RemoveAppender(xlog.NewAppender(buf))
+	if appenders := logging.GetAppenders(); len(appenders) != 1 || appenders[0] != a2 {
+		t.Fatal("expect only keepAppender, got: ", appenders)
+	}
+	logging.Logln(xlog.INFO, 0, nil, "removed")
+	if buf.Len() != 0 || len(a2.entries) != 1 {
+		t.Fatal("removed appender must not output: ", buf.String())
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package bench
+
+import (
+	"errors"
+	"github.com/xfali/xlog"
+	"testing"
+	"time"
+)
+
+// 丢弃数据但不是ioutil.Discard，Logging输出到ioutil.Discard时不会格式化
+type discardWriter struct{}
+
+func (w discardWriter) Write(d []byte) (int, error) {
+	return len(d), nil
+}
+
+func newBenchLogger(formatter xlog.Formatter, opts ...xlog.LoggingOpt) xlog.Logger {
+	logging := xlog.NewLogging(opts...)
+	logging.SetOutput(discardWriter{})
+	if formatter != nil {
+		logging.SetFormatter(formatter)
+	}
+	return xlog.NewFactory(logging).GetLogger("bench").With(xlog.String("service", "bench"), xlog.Int("shard", 3))
+}
+
+func benchmarkFormat(b *testing.B, logger xlog.Logger) {
+	err := errors.New("bench error")
+	b.Run("Infof", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			logger.Infof("request %s finished", "GET /index")
+		}
+	})
+	b.Run("Infoln", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			logger.Infoln("request finished")
+		}
+	})
+	b.Run("InfoFields", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			logger.InfoFields("request finished", xlog.Int("status", 200), xlog.Duration("cost", time.Second), xlog.Err(err))
+		}
+	})
+	b.Run("Parallel", func(b *testing.B) {
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				logger.Infoln("request finished")
+			}
+		})
+	})
+}
+
+func BenchmarkFormatBuiltin(b *testing.B) {
+	benchmarkFormat(b, newBenchLogger(nil))
+}
+
+func BenchmarkFormatBuiltinNoCaller(b *testing.B) {
+	benchmarkFormat(b, newBenchLogger(nil, xlog.SetCallerFlag(xlog.CallerNone)))
+}
+
+func BenchmarkFormatText(b *testing.B) {
+	benchmarkFormat(b, newBenchLogger(&xlog.TextFormatter{}))
+}
+
+func BenchmarkFormatJson(b *testing.B) {
+	benchmarkFormat(b, newBenchLogger(&xlog.JsonFormatter{}))
+}
@@ -8,10 +8,13 @@ package bench
 import (
 	"crypto/rand"
 	"encoding/base64"
+	"errors"
 	"github.com/xfali/xlog"
+	"io/ioutil"
 	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func BenchmarkLogger(b *testing.B) {
@@ -64,3 +67,16 @@ func BenchmarkMutableLogger(b *testing.B) {
 	wait.Wait()
 	b.Log(count)
 }
+
+func BenchmarkLoggerFields(b *testing.B) {
+	logging := xlog.NewLogging()
+	logging.SetOutput(ioutil.Discard)
+	logging.SetFormatter(&xlog.JsonFormatter{})
+	logger := xlog.NewFactory(logging).GetLogger("bench").With(xlog.String("service", "bench"))
+	err := errors.New("bench error")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.InfoFields("bench", xlog.Int("count", i), xlog.Duration("cost", time.Second), xlog.Err(err))
+	}
+}
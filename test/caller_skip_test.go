@@ -0,0 +1,125 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"bytes"
+	"github.com/xfali/xlog"
+	"gopkg.in/yaml.v2"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func logHelper(logger xlog.Logger, msg string) {
+	logHelperInner(logger, msg)
+}
+
+func logHelperInner(logger xlog.Logger, msg string) {
+	logger.WithDepth(5).Infoln(msg)
+}
+
+func currentLine() string {
+	_, _, line, _ := runtime.Caller(1)
+	return "caller_skip_test.go:" + strconv.Itoa(line+1)
+}
+
+func TestCallerSkip(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logging := xlog.NewLogging(xlog.SetCallerFlag(xlog.CallerShortFile|xlog.CallerShortFunc),
+		xlog.SetCallerSkipFunctions("github.com/xfali/xlog/test.logHelper"),
+		xlog.SetCallerSkipFunctions("github.com/xfali/xlog/test.logHelperInner"))
+	logging.SetOutput(buf)
+	wrapped := xlog.NewHookLevelLogging(xlog.NewSamplingLogging(logging), func(level xlog.Level) xlog.Level {
+		return level
+	})
+	logger := xlog.NewFactory(wrapped).GetLogger()
+
+	line := currentLine()
+	logHelper(logger, "skip helpers")
+	if !strings.Contains(buf.String(), line) || !strings.Contains(buf.String(), "TestCallerSkip") {
+		t.Fatal("expect caller ", line, " got: ", buf.String())
+	}
+
+	buf.Reset()
+	line = currentLine()
+	logger.WithDepth(3).Infoln("ignore depth")
+	if !strings.Contains(buf.String(), line) {
+		t.Fatal("expect caller ", line, " got: ", buf.String())
+	}
+
+	buf.Reset()
+	clone := logging.Clone()
+	clone.SetOutput(buf)
+	line = currentLine()
+	logHelper(xlog.NewFactory(clone).GetLogger(), "clone")
+	if !strings.Contains(buf.String(), line) {
+		t.Fatal("clone must keep caller skip, expect ", line, " got: ", buf.String())
+	}
+
+	buf.Reset()
+	logging = xlog.NewLogging(xlog.SetCallerFlag(xlog.CallerShortFile), xlog.SetCallerSkipPackages("github.com/xfali/..."))
+	logging.SetOutput(buf)
+	logging.Logln(xlog.INFO, 0, nil, "skip packages")
+	if !strings.Contains(buf.String(), "testing.go") {
+		t.Fatal("expect caller in testing, got: ", buf.String())
+	}
+}
+
+// 在yaml.v2（包路径最后一段包含'.'）的调用栈中输出日志
+type yamlLogValue struct {
+	logger xlog.Logger
+}
+
+func (v *yamlLogValue) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	v.logger.Infoln("yaml log")
+	return nil
+}
+
+func TestCallerSkipDotPackage(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logging := xlog.NewLogging(xlog.SetCallerFlag(xlog.CallerShortFile|xlog.CallerShortFunc),
+		xlog.SetCallerSkipPackages("gopkg.in/yaml.v2"),
+		xlog.SetCallerSkipFunctions("github.com/xfali/xlog/test.(*yamlLogValue).UnmarshalYAML"))
+	logging.SetOutput(buf)
+	v := &yamlLogValue{logger: xlog.NewFactory(logging).GetLogger()}
+
+	line := currentLine()
+	if err := yaml.Unmarshal([]byte("value"), v); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), line) || !strings.Contains(buf.String(), "TestCallerSkipDotPackage") {
+		t.Fatal("expect caller ", line, " got: ", buf.String())
+	}
+}
+
+func TestCallerFuncFlag(t *testing.T) {
+	for _, skip := range []bool{false, true} {
+		buf := &bytes.Buffer{}
+		opts := []xlog.LoggingOpt{xlog.SetCallerFlag(xlog.CallerShortFile)}
+		if skip {
+			opts = append(opts, xlog.SetCallerSkipFunctions("test.logHelper"))
+		}
+		logging := xlog.NewLogging(opts...)
+		logging.SetOutput(buf)
+		// 同一调用位置多次输出，结果应一致
+		for i := 0; i < 2; i++ {
+			logging.Logln(xlog.INFO, 0, nil, "no func")
+		}
+		if !strings.Contains(buf.String(), "caller_skip_test.go:") || strings.Contains(buf.String(), "TestCallerFuncFlag") {
+			t.Fatal("caller must not contain function: ", buf.String())
+		}
+
+		buf.Reset()
+		logging = xlog.NewLogging(append(opts, xlog.SetCallerFlag(xlog.CallerShortFile|xlog.CallerShortFunc))...)
+		logging.SetOutput(buf)
+		logging.Logln(xlog.INFO, 0, nil, "with func")
+		if !strings.Contains(buf.String(), "(TestCallerFuncFlag)") {
+			t.Fatal("caller must contain function: ", buf.String())
+		}
+	}
+}
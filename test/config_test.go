@@ -0,0 +1,283 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"github.com/xfali/xlog"
+	"github.com/xfali/xlog/config"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigBuild(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xlog_config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	errPath := filepath.Join(dir, "error.log")
+	data := `{
+		"level": "debug",
+		"loggers": {"db": "WARN"},
+		"caller": "shortFile|shortFunc",
+		"color": "disable",
+		"formatter": {"type": "json"},
+		"outputs": [
+			{"type": "stdout"},
+			{"levels": ["ERROR", "PANIC"], "type": "file", "path": "` + filepath.ToSlash(errPath) + `",
+			 "maxFileSize": 1048576, "rotateFrequency": "day", "compress": true,
+			 "async": {"flushInterval": "10ms", "block": true}}
+		],
+		"factory": {"mutable": true}
+	}`
+	path := filepath.Join(dir, "log.json")
+	if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := config.LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Outputs[1].Async == nil || time.Duration(c.Outputs[1].Async.FlushInterval) != 10*time.Millisecond {
+		t.Fatal("async config not match: ", c.Outputs[1].Async)
+	}
+	s, err := c.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !s.Logging.IsEnabled(xlog.DEBUG) || s.Logging.IsEnabledByName("db", xlog.INFO) {
+		t.Fatal("levels not match: ", s.Logging.GetSeverityLevels())
+	}
+	logger := s.Factory.GetLogger("test")
+	logger.Errorln("config error log")
+	logger.Debugln("config debug log")
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := ioutil.ReadFile(errPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ret := map[string]interface{}{}
+	if err := json.Unmarshal(d, &ret); err != nil {
+		t.Fatal(err, string(d))
+	}
+	if !strings.Contains(ret[xlog.KeyContent].(string), "config error log") || strings.Contains(string(d), "config debug log") {
+		t.Fatal("file content not match: ", string(d))
+	}
+}
+
+func TestConfigYaml(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xlog_config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	errPath := filepath.Join(dir, "error.log")
+	data := `
+level: debug
+loggers:
+  db: WARN
+caller: shortFile|shortFunc
+formatter:
+  type: json
+outputs:
+  - type: stdout
+  - levels: [ERROR, PANIC]
+    type: file
+    path: "` + filepath.ToSlash(errPath) + `"
+    rotateFrequency: day
+    async:
+      flushInterval: 10ms
+      block: true
+  - levels: [FATAL]
+    type: stderr
+    async:
+      flushInterval: 1000000
+`
+	path := filepath.Join(dir, "log.yaml")
+	if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := config.LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Level != "debug" || c.Loggers["db"] != "WARN" || c.Formatter.Type != "json" || len(c.Outputs) != 3 {
+		t.Fatal("config not match: ", c)
+	}
+	if c.Outputs[1].Async == nil || time.Duration(c.Outputs[1].Async.FlushInterval) != 10*time.Millisecond ||
+		time.Duration(c.Outputs[2].Async.FlushInterval) != time.Millisecond {
+		t.Fatal("async config not match: ", c.Outputs[1].Async, c.Outputs[2].Async)
+	}
+	s, err := c.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if !s.Logging.IsEnabled(xlog.DEBUG) || s.Logging.IsEnabledByName("db", xlog.INFO) {
+		t.Fatal("levels not match: ", s.Logging.GetSeverityLevels())
+	}
+}
+
+func TestConfigError(t *testing.T) {
+	for _, data := range []string{
+		`{"level": "unknown"}`,
+		`{"caller": "middleFile"}`,
+		`{"formatter": {"type": "xml"}}`,
+		`{"outputs": [{"type": "file"}]}`,
+		`{"outputs": [{"type": "file", "path": "x.log", "rotateFrequency": "week"}]}`,
+	} {
+		c, err := config.Parse([]byte(data), json.Unmarshal)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.Build(); err == nil {
+			t.Fatal("expect error: ", data)
+		}
+	}
+
+	if _, err := config.LoadFile("log.toml"); err == nil {
+		t.Fatal("expect no unmarshaler error")
+	}
+}
+
+func TestConfigShutdown(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xlog_config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := &config.Config{
+		Outputs: []config.OutputConfig{{Type: "file", Path: filepath.Join(dir, "app.log")}},
+	}
+	s, err := c.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Logging.Logln(xlog.INFO, 0, nil, "shutdown")
+	// 文件输出由SetOutput注册，Shutdown关闭后Setup.Close不返回错误
+	if err := xlog.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBindFlags(t *testing.T) {
+	os.Setenv("XLOG_LEVEL", "warn")
+	os.Setenv("XLOG_FORMAT", "text")
+	defer os.Unsetenv("XLOG_LEVEL")
+	defer os.Unsetenv("XLOG_FORMAT")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	f := config.BindFlags(fs)
+	if err := fs.Parse([]string{"-log.level", "debug", "-log.format", "json", "-log.caller", "longFile", "-log.output", "stderr"}); err != nil {
+		t.Fatal(err)
+	}
+	if f.Level != xlog.DEBUG || f.Format != "json" || f.Caller != "longFile" || f.Output != "stderr" {
+		t.Fatal("flags not match: ", f)
+	}
+
+	logging := xlog.NewLogging()
+	closer, err := f.Apply(logging)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closer.Close()
+	if !logging.IsEnabled(xlog.DEBUG) || logging.GetOutputBySeverity(xlog.INFO) != os.Stderr {
+		t.Fatal("apply failed")
+	}
+
+	f = config.BindFlags(flag.NewFlagSet("env", flag.ContinueOnError))
+	if f.Level != xlog.WARN || f.Format != "text" {
+		t.Fatal("env not match: ", f)
+	}
+
+	if err := fs.Parse([]string{"-log.level", "unknown"}); err == nil {
+		t.Fatal("expect error")
+	}
+}
+
+func TestBindFlagsApply(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logging := xlog.NewLogging(xlog.SetCallerFlag(xlog.CallerNone))
+	logging.SetSeverityLevel(xlog.WARN)
+	logging.SetOutput(buf)
+
+	// 未配置的项不修改Logging
+	f := config.BindFlags(flag.NewFlagSet("empty", flag.ContinueOnError))
+	closer, err := f.Apply(logging)
+	if err != nil {
+		t.Fatal(err)
+	}
+	closer.Close()
+	if logging.GetSeverityLevel() != xlog.WARN || logging.Config().CallerFlag != xlog.CallerNone {
+		t.Fatal("unset flags must not change logging")
+	}
+
+	// 命令行参数覆盖无效的环境变量
+	os.Setenv("XLOG_LEVEL", "unknown")
+	defer os.Unsetenv("XLOG_LEVEL")
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	f = config.BindFlags(fs)
+	if err := fs.Parse([]string{"-log.level", "error", "-log.caller", "shortFunc", "-log.color", "force"}); err != nil {
+		t.Fatal(err)
+	}
+	closer, err = f.Apply(logging)
+	if err != nil {
+		t.Fatal(err)
+	}
+	closer.Close()
+	conf := logging.Config()
+	if conf.Level != xlog.ERROR || conf.CallerFlag != xlog.CallerShortFunc || conf.ColorFlag != xlog.ForceColor {
+		t.Fatal("apply failed: ", conf.Level, conf.CallerFlag, conf.ColorFlag)
+	}
+	logging.Logln(xlog.ERROR, 0, nil, "test")
+	if !strings.Contains(buf.String(), "TestBindFlagsApply") {
+		t.Fatal("caller flag not applied: ", buf.String())
+	}
+
+	if _, err := config.BindFlags(flag.NewFlagSet("env", flag.ContinueOnError)).Apply(logging); err == nil {
+		t.Fatal("expect error")
+	}
+}
+
+func TestLevelText(t *testing.T) {
+	v := struct {
+		Level xlog.Level `json:"level"`
+	}{xlog.WARN}
+	d, err := json.Marshal(v)
+	if err != nil || string(d) != `{"level":"WARN"}` {
+		t.Fatal("marshal failed: ", string(d), err)
+	}
+	if err := json.Unmarshal([]byte(`{"level":"trace"}`), &v); err != nil || v.Level != xlog.TRACE {
+		t.Fatal("unmarshal failed: ", v.Level, err)
+	}
+	lv := xlog.Level(100)
+	if err := lv.UnmarshalText([]byte(lv.String())); err == nil {
+		t.Fatal("unregistered level must not be unmarshaled")
+	}
+	if err := lv.UnmarshalText([]byte("5")); err != nil || lv != xlog.DEBUG {
+		t.Fatal("unmarshal failed: ", lv, err)
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"bytes"
+	"context"
+	"github.com/xfali/xlog"
+	"strings"
+	"testing"
+)
+
+type ctxTenantKey struct{}
+
+func TestContextLogger(t *testing.T) {
+	xlog.RegisterContextExtractor(xlog.ContextValueExtractor(ctxTenantKey{}, "tenant"))
+	// 恢复默认的提取函数，避免影响其他测试
+	t.Cleanup(func() {
+		xlog.ClearContextExtractors()
+		xlog.RegisterContextExtractor(xlog.FieldsFromContext)
+	})
+	ctx := xlog.NewContext(context.Background(), "requestId", "req-1")
+	ctx = xlog.NewContext(ctx, "userId", "user-1")
+	ctx = context.WithValue(ctx, ctxTenantKey{}, "tenant-1")
+
+	t.Run("logger", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logging := xlog.NewLogging()
+		logging.SetOutput(buf)
+		logger := xlog.NewFactory(logging).GetLogger("ctx").WithFields("fixed", "value")
+		logger.WithContext(ctx).Infoln("with context")
+		logger.Infoln("without context")
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if len(lines) != 2 {
+			t.Fatal("expect 2 lines, got: ", buf.String())
+		}
+		for _, v := range []string{"req-1", "user-1", "tenant-1", "value", "ctx"} {
+			if !strings.Contains(lines[0], v) {
+				t.Fatal("expect ", v, " in ", lines[0])
+			}
+		}
+		if strings.Contains(lines[1], "req-1") {
+			t.Fatal("parent logger must not be changed: ", lines[1])
+		}
+	})
+
+	t.Run("mutable logger", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logging := xlog.NewLogging()
+		logging.SetOutput(buf)
+		logger := xlog.NewMutableFactory(logging).GetLogger("ctx")
+		logger.WithContext(ctx).Infof("with context")
+		if !strings.Contains(buf.String(), "req-1") || !strings.Contains(buf.String(), "tenant-1") {
+			t.Fatal("expect context fields, got: ", buf.String())
+		}
+	})
+
+	t.Run("default", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logging := xlog.NewLogging()
+		logging.SetOutput(buf)
+		xlog.ResetLogging(logging)
+		xlog.WithFields("global", "field")
+		defer xlog.WithFields()
+
+		xlog.InfolnCtx(ctx, "with context")
+		if !strings.Contains(buf.String(), "req-1") || !strings.Contains(buf.String(), "field") {
+			t.Fatal("expect context fields, got: ", buf.String())
+		}
+		buf.Reset()
+		xlog.Infoln("without context")
+		if strings.Contains(buf.String(), "req-1") {
+			t.Fatal("global fields must not be changed: ", buf.String())
+		}
+	})
+}
@@ -0,0 +1,152 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"errors"
+	"github.com/xfali/xlog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDedup(t *testing.T) {
+	buf := &syncBuffer{}
+	logging := xlog.NewLogging(xlog.SetCallerFlag(xlog.CallerShortFile))
+	logging.SetOutput(buf)
+	dedup := xlog.NewDedupLogging(logging, xlog.SetDedupWindow(time.Hour))
+	logger := xlog.NewFactory(dedup).GetLogger("health")
+
+	for i := 0; i < 38; i++ {
+		logger.Warnln("health check failed")
+	}
+	if dedup.Suppressed() != 37 {
+		t.Fatal("expect 37 suppressed, got: ", dedup.Suppressed())
+	}
+	logger.Warnln("health check failed", "db")
+	logger.WithFields("target", "db").Warnln("health check failed", "db")
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatal("expect 4 lines, got: ", buf.String())
+	}
+	if !strings.Contains(lines[0], "health check failed") || !strings.Contains(lines[0], "dedup_test.go") {
+		t.Fatal("first line not match: ", lines[0])
+	}
+	if !strings.Contains(lines[1], "last message repeated 37 times") || !strings.Contains(lines[1], "dedup_test.go") {
+		t.Fatal("summary line not match: ", lines[1])
+	}
+	if strings.Count(lines[3], "db") != 2 {
+		t.Fatal("different fields must be output: ", lines[3])
+	}
+}
+
+func TestDedupWindow(t *testing.T) {
+	buf := &syncBuffer{}
+	logging := xlog.NewLogging()
+	logging.SetOutput(buf)
+	dedup := xlog.NewDedupLogging(logging, xlog.SetDedupWindow(50*time.Millisecond))
+
+	for i := 0; i < 3; i++ {
+		dedup.Logf(xlog.INFO, 0, nil, "ping %d", 1)
+	}
+	time.Sleep(200 * time.Millisecond)
+	out := buf.String()
+	if !strings.Contains(out, "last message repeated 2 times") {
+		t.Fatal("expect summary after window: ", out)
+	}
+	dedup.Logf(xlog.INFO, 0, nil, "ping %d", 1)
+	dedup.Logf(xlog.INFO, 0, nil, "ping %d", 1)
+	dedup.Flush()
+	if n := strings.Count(buf.String(), "ping 1"); n != 2 {
+		t.Fatal("expect new window after expire, got: ", buf.String())
+	}
+	if n := strings.Count(buf.String(), "last message repeated 1 times"); n != 1 {
+		t.Fatal("expect summary after Flush, got: ", buf.String())
+	}
+}
+
+// 输出的Writer再次调用DedupLogging，输出在锁内执行时会死锁
+type reentrantWriter struct {
+	buf   syncBuffer
+	dedup xlog.Logging
+}
+
+func (w *reentrantWriter) Write(p []byte) (int, error) {
+	if strings.Contains(string(p), "outer") {
+		w.dedup.Logln(xlog.WARN, 0, nil, "inner")
+	}
+	return w.buf.Write(p)
+}
+
+func TestDedupOutputUnlocked(t *testing.T) {
+	w := &reentrantWriter{}
+	logging := xlog.NewLogging()
+	logging.SetOutput(w)
+	dedup := xlog.NewDedupLogging(logging, xlog.SetDedupWindow(time.Hour))
+	w.dedup = dedup
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		dedup.Logln(xlog.INFO, 0, nil, "outer")
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("output must not hold the lock")
+	}
+	if !strings.Contains(w.buf.String(), "inner") {
+		t.Fatal("expect inner log: ", w.buf.String())
+	}
+}
+
+func TestDedupLazy(t *testing.T) {
+	buf := &syncBuffer{}
+	logging := xlog.NewLogging()
+	logging.SetOutput(buf)
+	dedup := xlog.NewDedupLogging(logging, xlog.SetDedupWindow(time.Hour))
+
+	count := 0
+	lazy := xlog.LazyField("state", func() interface{} {
+		count++
+		return "ok"
+	})
+	for i := 0; i < 3; i++ {
+		dedup.Logln(xlog.INFO, 0, &xlog.Fields{lazy}, "check")
+	}
+	// 只在输出时求值，比较时不求值
+	if count != 3 || dedup.Suppressed() != 0 {
+		t.Fatal("lazy value must be evaluated only on output: ", count, dedup.Suppressed())
+	}
+}
+
+func TestDedupArgs(t *testing.T) {
+	buf := &syncBuffer{}
+	logging := xlog.NewLogging()
+	logging.SetOutput(buf)
+	dedup := xlog.NewDedupLogging(logging, xlog.SetDedupWindow(time.Hour))
+
+	count := 0
+	lazy := xlog.Lazy(func() interface{} {
+		count++
+		return "ok"
+	})
+	for i := 0; i < 3; i++ {
+		dedup.Logf(xlog.INFO, 0, nil, "state: %v", lazy)
+	}
+	// 比较时不格式化参数，每次输出只求值一次
+	if count != 3 || dedup.Suppressed() != 0 {
+		t.Fatal("lazy arg must be evaluated once per output: ", count, dedup.Suppressed())
+	}
+
+	// error参数比较错误信息
+	for i := 0; i < 3; i++ {
+		dedup.Logln(xlog.WARN, 0, nil, "request failed", errors.New("timeout"), []int{1, 2})
+	}
+	if dedup.Suppressed() != 2 {
+		t.Fatal("expect 2 suppressed, got: ", dedup.Suppressed())
+	}
+}
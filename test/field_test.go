@@ -0,0 +1,157 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/xfali/xlog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTypedFields(t *testing.T) {
+	now := time.Now()
+	fields := xlog.NewFields(
+		xlog.String("string", "test\t\"quote\""),
+		xlog.Int("int", 1),
+		xlog.Int64("int64", -2),
+		xlog.Uint64("uint64", 3),
+		xlog.Float64("float", 1.1),
+		xlog.Bool("bool", true),
+		xlog.Duration("duration", time.Second),
+		xlog.Time("time", now),
+		xlog.Err(errors.New("test error")),
+		xlog.Any("any", []int{1, 2}),
+		xlog.Any("nil", nil),
+	)
+
+	t.Run("keyValues", func(t *testing.T) {
+		if fields.Len() != 11 {
+			t.Fatal("expect 11 fields, got: ", fields.Len())
+		}
+		fields.Add("int", 2, "new", "value")
+		if fields.Get("int").(int) != 2 || fields.Get("new").(string) != "value" {
+			t.Fatal("add failed: ", fields.GetAll())
+		}
+		if !fields.Get("time").(time.Time).Equal(now) {
+			t.Fatal("time not match: ", fields.Get("time"))
+		}
+		fields.Remove("new")
+		if fields.Get("new") != nil || fields.Len() != 11 {
+			t.Fatal("remove failed: ", fields.GetAll())
+		}
+		if fields.Add(1, 2) == nil {
+			t.Fatal("key must be string")
+		}
+	})
+
+	t.Run("text", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		f := xlog.TextFormatter{}
+		err := f.Format(buf, fields)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Log(buf.String())
+		for _, v := range []string{"int=2 ", "int64=-2 ", "float=1.1 ", "bool=true ", "duration=1s ", "LogError=test error ", "any=[1 2] "} {
+			if !strings.Contains(buf.String(), v) {
+				t.Fatal("expect ", v)
+			}
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		f := xlog.JsonFormatter{}
+		err := f.Format(buf, fields)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Log(buf.String())
+		m := map[string]interface{}{}
+		err = json.Unmarshal(buf.Bytes(), &m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m["string"] != "test\t\"quote\"" || m["int"] != 2.0 || m["bool"] != true || m["LogError"] != "test error" || m["nil"] != nil {
+			t.Fatal("json not match: ", m)
+		}
+		if v, err := time.Parse(time.RFC3339Nano, m["time"].(string)); err != nil || !v.Equal(now) {
+			t.Fatal("time not match: ", m["time"])
+		}
+	})
+}
+
+func TestLoggerTypedFields(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logging := xlog.NewLogging()
+	logging.SetOutput(buf)
+	logging.SetFormatter(&xlog.JsonFormatter{})
+	logger := xlog.NewFactory(logging).GetLogger("typed").With(xlog.String("service", "test"))
+	logger.InfoFields("typed fields", xlog.Int("count", 10), xlog.Err(errors.New("failed")))
+	logger.DebugFields("cannot be here", xlog.Int("count", 11))
+
+	m := map[string]interface{}{}
+	err := json.Unmarshal(buf.Bytes(), &m)
+	if err != nil {
+		t.Fatal(err, buf.String())
+	}
+	if m["service"] != "test" || m["count"] != 10.0 || m[xlog.KeyError] != "failed" || m[xlog.KeyName] != "typed" {
+		t.Fatal("json not match: ", m)
+	}
+	if !strings.HasPrefix(m[xlog.KeyContent].(string), "typed fields") {
+		t.Fatal("content not match: ", m[xlog.KeyContent])
+	}
+}
+
+func TestFloat32Field(t *testing.T) {
+	if v, ok := xlog.Any("f", float32(1.1)).Value().(float32); !ok || v != float32(1.1) {
+		t.Fatal("float32 value not match")
+	}
+
+	buf := &bytes.Buffer{}
+	logging := xlog.NewLogging()
+	logging.SetOutput(buf)
+	logger := xlog.NewFactory(logging).GetLogger()
+	for _, f := range []xlog.Formatter{nil, &xlog.TextFormatter{}, &xlog.JsonFormatter{}} {
+		buf.Reset()
+		logging.SetFormatter(f)
+		logger.WithFields("f", float32(1.1)).InfoFields("float32", xlog.Float32("g", 2.2))
+		if !strings.Contains(buf.String(), "1.1") || !strings.Contains(buf.String(), "2.2") ||
+			strings.Contains(buf.String(), "1.100000023841858") || strings.Contains(buf.String(), "2.200000047") {
+			t.Fatalf("%T float32 not match: %s", f, buf.String())
+		}
+	}
+}
+
+// 在调用返回后仍使用keyValues的Logging实现
+type retainLogging struct {
+	xlog.Logging
+	kvs []xlog.KeyValues
+}
+
+func (l *retainLogging) Logln(level xlog.Level, depth int, keyValues xlog.KeyValues, args ...interface{}) {
+	l.kvs = append(l.kvs, keyValues)
+}
+
+func TestFieldsRetained(t *testing.T) {
+	logging := &retainLogging{Logging: xlog.NewLogging()}
+	logger := xlog.NewFactory(logging).GetLogger().WithFields("count", 1)
+	logger.InfoFields("first", xlog.String("key", "v1"))
+	logger.InfoFields("second", xlog.String("key", "v2"))
+	if len(logging.kvs) != 2 {
+		t.Fatal("expect 2 logs, got: ", len(logging.kvs))
+	}
+	if logging.kvs[0].Get("key") != "v1" || logging.kvs[1].Get("key") != "v2" {
+		t.Fatal("keyValues must not be reused: ", logging.kvs[0].GetAll(), logging.kvs[1].GetAll())
+	}
+	if v, ok := logging.kvs[0].Get("count").(int); !ok || v != 1 {
+		t.Fatal("expect int value, got: ", logging.kvs[0].Get("count"))
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"bytes"
+	"github.com/xfali/xlog"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+type countStringer struct {
+	count int
+}
+
+func (s *countStringer) String() string {
+	s.count++
+	return "stringer"
+}
+
+func TestFilterCombine(t *testing.T) {
+	accept := xlog.FilterFunc(func(entry *xlog.Entry) xlog.FilterResult { return xlog.FilterAccept })
+	deny := xlog.FilterFunc(func(entry *xlog.Entry) xlog.FilterResult { return xlog.FilterDeny })
+	neutral := xlog.FilterFunc(func(entry *xlog.Entry) xlog.FilterResult { return xlog.FilterNeutral })
+	entry := &xlog.Entry{}
+
+	cases := []struct {
+		filter xlog.Filter
+		expect xlog.FilterResult
+	}{
+		{xlog.And(accept, accept), xlog.FilterAccept},
+		{xlog.And(accept, neutral), xlog.FilterNeutral},
+		{xlog.And(neutral, deny), xlog.FilterDeny},
+		{xlog.Or(deny, deny), xlog.FilterDeny},
+		{xlog.Or(deny, neutral), xlog.FilterNeutral},
+		{xlog.Or(neutral, accept), xlog.FilterAccept},
+		{xlog.Not(accept), xlog.FilterDeny},
+		{xlog.Not(neutral), xlog.FilterNeutral},
+		{xlog.Chain(neutral, deny, accept), xlog.FilterDeny},
+		{xlog.AcceptOnMatch(deny), xlog.FilterNeutral},
+		{xlog.DenyOnMatch(accept), xlog.FilterDeny},
+	}
+	for i, c := range cases {
+		if ret := c.filter.Filter(entry); ret != c.expect {
+			t.Fatal("case ", i, " expect ", c.expect, " got ", ret)
+		}
+	}
+}
+
+func TestLoggingFilter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logging := xlog.NewLogging()
+	logging.SetOutput(buf)
+	logging.AddFilter(xlog.AcceptOnMatch(xlog.ThresholdFilter(xlog.ERROR)))
+	logging.AddFilter(xlog.DenyOnMatch(xlog.NameFilter("chatty")))
+	logging.AddFilter(xlog.DenyOnMatch(xlog.Or(
+		xlog.FieldFilter("status", 404),
+		xlog.MessageFilter(regexp.MustCompile(`^health check`)))))
+	fac := xlog.NewFactory(logging)
+
+	s := &countStringer{}
+	chatty := fac.GetLogger("chatty")
+	chatty.WithName("sub").Infof("chatty %v", s)
+	chatty.Infoln("chatty", s)
+	if s.count != 0 {
+		t.Fatal("denied log must not be formatted")
+	}
+	chatty.Errorf("chatty error %v", s)
+
+	logger := fac.GetLogger("app")
+	logger.With(xlog.Int("status", 404)).Infoln("not found")
+	logger.Infof("health check %s", "ok")
+	logger.Infof("request %v", s)
+
+	out := buf.String()
+	if strings.Contains(out, "not found") || strings.Contains(out, "health check") || strings.Contains(out, "chatty stringer") {
+		t.Fatal("unexpected log: ", out)
+	}
+	if !strings.Contains(out, "chatty error stringer") || !strings.Contains(out, "request stringer") || s.count != 2 {
+		t.Fatal("expect logs, got: ", out, s.count)
+	}
+}
+
+func TestLoggingFilterFatal(t *testing.T) {
+	buf := &bytes.Buffer{}
+	exitCode := 0
+	var panicValue interface{}
+	logging := xlog.NewLogging(xlog.SetExitFunc(func(code int) { exitCode = code }),
+		xlog.SetPanicFunc(func(v interface{}) { panicValue = v }))
+	logging.SetOutput(buf)
+	logging.AddFilter(xlog.DenyOnMatch(xlog.NameFilter("thirdparty")))
+	logger := xlog.NewFactory(logging).GetLogger("thirdparty")
+
+	logger.Fatalln("fatal")
+	if exitCode == 0 {
+		t.Fatal("filtered fatal must exit")
+	}
+	logger.Panicln("panic")
+	if panicValue == nil {
+		t.Fatal("filtered panic must panic")
+	}
+	if buf.Len() != 0 {
+		t.Fatal("filtered entry must not be written: ", buf.String())
+	}
+}
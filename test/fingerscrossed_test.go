@@ -0,0 +1,138 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"bytes"
+	"github.com/xfali/xlog"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestFingersCrossed(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logging := xlog.NewLogging(xlog.SetCallerFlag(xlog.CallerShortFile))
+	logging.SetSeverityLevel(xlog.INFO)
+	logging.SetOutput(buf)
+	logger := xlog.NewFactory(logging).GetLogger("handler").WithFields("reqId", "r1")
+
+	reqLogger, fc := xlog.NewFingersCrossedLogger(logger)
+	if !reqLogger.DebugEnabled() {
+		t.Fatal("debug must be enabled while buffering")
+	}
+	reqLogger.Debugln("debug detail")
+	reqLogger.WithName("db").Infof("query %d", 1)
+	if buf.Len() != 0 {
+		t.Fatal("expect buffered, got: ", buf.String())
+	}
+	fc.Discard()
+	reqLogger.Errorln("failed")
+	if !fc.Triggered() {
+		t.Fatal("expect triggered")
+	}
+	if strings.Contains(buf.String(), "debug detail") || !strings.Contains(buf.String(), "failed") {
+		t.Fatal("discarded log must not be output: ", buf.String())
+	}
+
+	buf.Reset()
+	fc.Reset()
+	reqLogger.Debugln("debug detail")
+	reqLogger.WithName("db").Infof("query %d", 2)
+	reqLogger.Errorln("failed")
+	reqLogger.Debugln("after trigger")
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatal("expect 3 lines, got: ", buf.String())
+	}
+	if !strings.Contains(lines[0], "DEBUG") || !strings.Contains(lines[0], "debug detail") ||
+		!strings.Contains(lines[0], "r1") || !strings.Contains(lines[0], "fingerscrossed_test.go") {
+		t.Fatal("buffered log must keep fields and caller: ", lines[0])
+	}
+	if !strings.Contains(lines[1], "handler.db") || !strings.Contains(lines[1], "query 2") {
+		t.Fatal("buffered log must keep name: ", lines[1])
+	}
+	if !strings.Contains(lines[2], "failed") {
+		t.Fatal("trigger log must be output after buffered logs: ", lines[2])
+	}
+	if logging.IsEnabled(xlog.DEBUG) {
+		t.Fatal("replay must not change logging level")
+	}
+}
+
+func TestFingersCrossedLimit(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logging := xlog.NewLogging()
+	logging.SetOutput(buf)
+	logging.SetFormatter(&xlog.TextFormatter{})
+	fc := xlog.NewFingersCrossedLogging(logging, xlog.SetFingersCrossedTrigger(xlog.WARN), xlog.SetFingersCrossedLimit(2, 0))
+	for i := 0; i < 5; i++ {
+		fc.Logf(xlog.INFO, 0, nil, "info %d", i)
+	}
+	if fc.Dropped() != 3 {
+		t.Fatal("expect 3 dropped, got: ", fc.Dropped())
+	}
+	fc.Logln(xlog.WARN, 0, nil, "warn")
+	out := buf.String()
+	if strings.Contains(out, "info 2") || !strings.Contains(out, "info 3") || !strings.Contains(out, "info 4") {
+		t.Fatal("expect oldest dropped: ", out)
+	}
+	if !strings.Contains(out, xlog.KeyBufferDropped+"=3 ") || strings.Count(out, xlog.KeyBufferDropped) != 1 ||
+		strings.Count(out, xlog.KeyContent+"=") != 3 {
+		t.Fatal("expect dropped count and 3 lines: ", out)
+	}
+	if fc.Dropped() != 0 {
+		t.Fatal("expect dropped reset after flush, got: ", fc.Dropped())
+	}
+
+	// 丢弃及重置缓存时清零，不影响下一个工作单元
+	for _, reset := range []func(){fc.Discard, fc.Reset} {
+		fc.Reset()
+		for i := 0; i < 5; i++ {
+			fc.Logf(xlog.INFO, 0, nil, "info %d", i)
+		}
+		reset()
+		if fc.Dropped() != 0 {
+			t.Fatal("expect dropped cleared, got: ", fc.Dropped())
+		}
+	}
+	fc.Reset()
+	buf.Reset()
+	fc.Logln(xlog.INFO, 0, nil, "next unit")
+	fc.Logln(xlog.WARN, 0, nil, "warn")
+	if strings.Contains(buf.String(), xlog.KeyBufferDropped) {
+		t.Fatal("stale dropped count: ", buf.String())
+	}
+}
+
+func TestFingersCrossedReplay(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logging := xlog.NewLogging(xlog.SetCallerFlag(xlog.CallerShortFile|xlog.CallerShortFunc),
+		xlog.SetCallerSkipFunctions("github.com/xfali/xlog/test.logHelper"),
+		xlog.SetCallerSkipFunctions("github.com/xfali/xlog/test.logHelperInner"))
+	logging.SetOutput(buf)
+	logger, _ := xlog.NewFingersCrossedLogger(xlog.NewFactory(logging).GetLogger())
+
+	// 缓存时使用Logging的调用者配置
+	_, _, n, _ := runtime.Caller(0)
+	logHelper(logger, "buffered")
+	line := "fingerscrossed_test.go:" + strconv.Itoa(n+1)
+	logger.Errorln("failed")
+	if !strings.Contains(buf.String(), line) || !strings.Contains(buf.String(), "TestFingersCrossedReplay") {
+		t.Fatal("expect caller ", line, " got: ", buf.String())
+	}
+
+	// 回放使用原Logging输出，失败统计不丢失
+	logging = xlog.NewLogging()
+	logging.SetOutput(failedWriter{})
+	fc := xlog.NewFingersCrossedLogging(logging)
+	fc.Logln(xlog.DEBUG, 0, nil, "buffered")
+	fc.Logln(xlog.ERROR, 0, nil, "failed")
+	if stats := logging.GetErrorStats(); stats.WriteErrors != 2 {
+		t.Fatal("expect 2 write errors, got: ", stats.WriteErrors)
+	}
+}
@@ -6,9 +6,12 @@
 package test
 
 import (
+	"bytes"
+	"encoding/json"
 	"github.com/xfali/xlog"
 	"os"
 	"sort"
+	"strings"
 	"testing"
 	"time"
 )
@@ -116,3 +119,45 @@ func TestJsonFormatter(t *testing.T) {
 		}
 	})
 }
+
+func TestFormatterBySeverity(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logging := xlog.NewLogging()
+	logging.SetOutput(buf)
+	logging.SetSeverityLevel(xlog.TRACE)
+	jsonFormatter, textFormatter := &xlog.JsonFormatter{}, &xlog.TextFormatter{}
+	logging.SetFormatterBySeverity(xlog.ERROR, jsonFormatter)
+	logging.SetFormatterBySeverity(xlog.DEBUG, textFormatter)
+
+	if logging.GetFormatterBySeverity(xlog.ERROR) != jsonFormatter || logging.GetFormatterBySeverity(xlog.WARN) != nil ||
+		logging.GetFormatter() != nil {
+		t.Fatal("formatter not match")
+	}
+	if conf := logging.Config(); len(conf.Formatters) != 2 || conf.Formatters[xlog.DEBUG] != textFormatter {
+		t.Fatal("config formatters not match: ", conf.Formatters)
+	}
+
+	logging.Logln(xlog.ERROR, 0, nil, "error")
+	logging.Logln(xlog.WARN, 0, nil, "warn")
+	logging.Logln(xlog.DEBUG, 0, nil, "debug")
+	logging.Logln(xlog.TRACE, 0, nil, "trace")
+	out := buf.String()
+	ret := map[string]interface{}{}
+	if err := json.NewDecoder(buf).Decode(&ret); err != nil || ret[xlog.KeySeverityLevel] != "ERROR" {
+		t.Fatal("error must be json: ", out)
+	}
+	// WARN未配置，使用较轻的DEBUG级别的Formatter；TRACE之后没有配置的级别，使用内置格式
+	if strings.Count(out, "{") != 1 || !strings.Contains(out, xlog.KeySeverityLevel+"=WARN") ||
+		!strings.Contains(out, xlog.KeySeverityLevel+"=DEBUG") || !strings.Contains(out, "[TRACE]") {
+		t.Fatal("formatter not match: ", out)
+	}
+
+	logging.SetFormatterBySeverity(xlog.ERROR, nil)
+	if logging.GetFormatterBySeverity(xlog.ERROR) != nil || logging.GetFormatterBySeverity(xlog.DEBUG) != textFormatter {
+		t.Fatal("nil formatter must delete the level")
+	}
+	logging.SetFormatter(jsonFormatter)
+	if logging.GetFormatterBySeverity(xlog.DEBUG) != nil || logging.GetFormatter() != jsonFormatter {
+		t.Fatal("SetFormatter must clear formatters by severity")
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/xfali/xlog"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestLazy(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logging := xlog.NewLogging()
+	logging.SetSeverityLevel(xlog.INFO)
+	logging.SetOutput(buf)
+	logging.SetFormatter(&xlog.JsonFormatter{})
+	logging.AddFilter(xlog.DenyOnMatch(xlog.MessageFilter(regexp.MustCompile(`deny`))))
+
+	count := 0
+	state := xlog.Lazy(func() interface{} {
+		count++
+		return 3.14159
+	})
+	dump := xlog.LazyString(func() string {
+		count++
+		return "dump"
+	})
+	logger := xlog.NewFactory(logging).GetLogger().WithFields("state", state)
+	logger.Debugf("state %v %s", state, dump)
+	logger.With(xlog.LazyField("dump", func() interface{} {
+		count++
+		return "x"
+	})).Infoln("deny")
+	if count != 0 {
+		t.Fatal("lazy values must not be evaluated, got: ", count)
+	}
+
+	logger.Infof("state %5.2f %s", state, dump)
+	out := buf.String()
+	if count != 3 || !strings.Contains(out, `"state":3.14159`) || !strings.Contains(out, "state  3.14 dump") {
+		t.Fatal("lazy values not match: ", count, out)
+	}
+	if fmt.Sprint(dump) != "dump" || fmt.Sprintf("%+v", state) != "3.14159" {
+		t.Fatal("lazy values must implement fmt interfaces")
+	}
+}
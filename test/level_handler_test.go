@@ -0,0 +1,79 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"encoding/json"
+	"github.com/xfali/xlog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLevelHandler(t *testing.T) {
+	logging := xlog.NewLogging()
+	handler := xlog.NewLevelHandler(logging)
+
+	do := func(method, target, contentType, body string) (int, map[string]interface{}) {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, req)
+		ret := map[string]interface{}{}
+		if err := json.Unmarshal(resp.Body.Bytes(), &ret); err != nil {
+			t.Fatal(err, resp.Body.String())
+		}
+		return resp.Code, ret
+	}
+
+	code, ret := do(http.MethodGet, "/", "", "")
+	if code != http.StatusOK || ret["level"] != "INFO" {
+		t.Fatal("expect INFO, got: ", code, ret)
+	}
+
+	code, ret = do(http.MethodPut, "/", "application/json", `{"level":"debug"}`)
+	if code != http.StatusOK || ret["level"] != "DEBUG" || !logging.IsEnabled(xlog.DEBUG) {
+		t.Fatal("expect DEBUG, got: ", code, ret)
+	}
+
+	code, ret = do(http.MethodPost, "/", "application/x-www-form-urlencoded", "name=db&level=WARN")
+	if code != http.StatusOK || ret["name"] != "db" || ret["level"] != "WARN" || logging.IsEnabledByName("db", xlog.INFO) {
+		t.Fatal("expect db WARN, got: ", code, ret)
+	}
+
+	code, ret = do(http.MethodPut, "/", "", `{"loggers":{"db.pool":"ERROR"}}`)
+	if code != http.StatusOK || ret["level"] != "DEBUG" {
+		t.Fatal("expect DEBUG, got: ", code, ret)
+	}
+	loggers := ret["loggers"].(map[string]interface{})
+	if loggers["db"] != "WARN" || loggers["db.pool"] != "ERROR" {
+		t.Fatal("loggers not match: ", loggers)
+	}
+
+	code, ret = do(http.MethodGet, "/?name=db.pool.conn", "", "")
+	if code != http.StatusOK || ret["level"] != "ERROR" {
+		t.Fatal("expect ERROR, got: ", code, ret)
+	}
+
+	for _, body := range []string{`{"level":"unknown"}`, `{"level":"99"}`, `{"level":"-5"}`, `{"loggers":{"db":"7"}}`,
+		`{"level":"DEBUG","name":"` + strings.Repeat("x", 1<<20) + `"}`} {
+		code, ret = do(http.MethodPut, "/", "", body)
+		if code != http.StatusBadRequest || ret["error"] == nil {
+			t.Fatal("expect bad request, got: ", code, ret)
+		}
+	}
+	if !logging.IsEnabled(xlog.DEBUG) || logging.IsEnabledByName("db", xlog.INFO) {
+		t.Fatal("invalid request must not change levels")
+	}
+
+	code, _ = do(http.MethodDelete, "/", "", "")
+	if code != http.StatusMethodNotAllowed {
+		t.Fatal("expect method not allowed, got: ", code)
+	}
+}
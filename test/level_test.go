@@ -0,0 +1,196 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"bytes"
+	"github.com/xfali/xlog"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	lv, err := xlog.ParseLevel("debug")
+	if err != nil || lv != xlog.DEBUG {
+		t.Fatal("expect DEBUG, got: ", lv, err)
+	}
+	lv, err = xlog.ParseLevel("3")
+	if err != nil || lv != xlog.WARN {
+		t.Fatal("expect WARN, got: ", lv, err)
+	}
+	for _, s := range []string{"unknown", "99", "-5", "Level(3)"} {
+		if _, err = xlog.ParseLevel(s); err == nil {
+			t.Fatal("expect error: ", s)
+		}
+	}
+
+	levels, err := xlog.ParseSeverityLevels("root=INFO, db=DEBUG;db.pool=WARN")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(levels) != 3 || levels["root"] != xlog.INFO || levels["db"] != xlog.DEBUG || levels["db.pool"] != xlog.WARN {
+		t.Fatal("levels not match: ", levels)
+	}
+	_, err = xlog.ParseSeverityLevels("db")
+	if err == nil {
+		t.Fatal("expect error")
+	}
+}
+
+func TestLevelByName(t *testing.T) {
+	levels, err := xlog.ParseSeverityLevels("root=WARN,db=DEBUG,db.pool=ERROR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := &bytes.Buffer{}
+	logging := xlog.NewLogging(xlog.SetLevelsByName(levels))
+	logging.SetOutput(buf)
+
+	for _, fac := range []xlog.LoggerFactory{xlog.NewFactory(logging), xlog.NewMutableFactory(logging)} {
+		buf.Reset()
+		root := fac.GetLogger()
+		db := fac.GetLogger("db")
+		dbx := fac.GetLogger("dbx")
+		pool := db.WithName("pool")
+		conn := pool.WithName("conn")
+
+		if root.InfoEnabled() || !root.WarnEnabled() {
+			t.Fatal("root must be WARN")
+		}
+		if !db.DebugEnabled() || dbx.InfoEnabled() {
+			t.Fatal("db must be DEBUG and dbx must be WARN")
+		}
+		if pool.WarnEnabled() || !pool.ErrorEnabled() || conn.WarnEnabled() {
+			t.Fatal("db.pool and db.pool.conn must be ERROR")
+		}
+
+		db.Debugln("db debug")
+		dbx.Infoln("dbx info")
+		pool.Warnln("pool warn")
+		conn.Errorln("conn error")
+		root.Infoln("root info")
+		if !strings.Contains(buf.String(), "db debug") || !strings.Contains(buf.String(), "conn error") {
+			t.Fatal("expect db debug and conn error, got: ", buf.String())
+		}
+		if strings.Contains(buf.String(), "dbx info") || strings.Contains(buf.String(), "pool warn") || strings.Contains(buf.String(), "root info") {
+			t.Fatal("unexpected log: ", buf.String())
+		}
+	}
+
+	logging.SetSeverityLevelByName("db.pool", xlog.DEBUG)
+	logging.SetSeverityLevelByName(xlog.RootLoggerName, xlog.INFO)
+	got := logging.GetSeverityLevels()
+	if got["db.pool"] != xlog.DEBUG || got[xlog.RootLoggerName] != xlog.INFO || !logging.IsEnabled(xlog.INFO) {
+		t.Fatal("levels not match: ", got)
+	}
+	if !logging.Clone().IsEnabledByName("db.pool.conn", xlog.DEBUG) {
+		t.Fatal("clone must keep levels")
+	}
+}
+
+func TestRegisterLevel(t *testing.T) {
+	const AUDIT = xlog.Level(-1)
+	xlog.RegisterLevel(AUDIT, "AUDIT", xlog.ForeMagenta, nil)
+	t.Cleanup(func() {
+		xlog.UnregisterLevel(AUDIT)
+	})
+	if AUDIT.String() != "AUDIT" || xlog.TRACE.String() != "TRACE" {
+		t.Fatal("level name not match: ", AUDIT, xlog.TRACE)
+	}
+	if lv, err := xlog.ParseLevel("audit"); err != nil || lv != AUDIT {
+		t.Fatal("parse AUDIT failed: ", lv, err)
+	}
+	levels := xlog.Levels()
+	if levels[0] != AUDIT || levels[len(levels)-1] != xlog.TRACE {
+		t.Fatal("levels not sorted: ", levels)
+	}
+
+	buf := &bytes.Buffer{}
+	auditBuf := &bytes.Buffer{}
+	logging := xlog.NewLogging()
+	logging.SetOutput(buf)
+	logging.SetOutputBySeverity(AUDIT, auditBuf)
+	logging.SetSeverityLevel(xlog.ERROR)
+	logger := xlog.NewFactory(logging).GetLogger()
+
+	logger.Logln(AUDIT, "audit log")
+	logger.Logf(xlog.TRACE, "trace log")
+	if !strings.Contains(auditBuf.String(), "[AUDIT]") || !strings.Contains(auditBuf.String(), "audit log") {
+		t.Fatal("expect audit log, got: ", auditBuf.String())
+	}
+	if buf.Len() != 0 {
+		t.Fatal("trace must not be logged: ", buf.String())
+	}
+
+	logging.SetSeverityLevel(xlog.TRACE)
+	if !logger.IsEnabled(xlog.TRACE) {
+		t.Fatal("trace must be enabled")
+	}
+	logger.Log(xlog.TRACE, "trace log")
+	if !strings.Contains(buf.String(), "[TRACE]") {
+		t.Fatal("expect trace log, got: ", buf.String())
+	}
+}
+
+func TestRegisterLevelVerbose(t *testing.T) {
+	const VERBOSE = xlog.TRACE + 1
+	xlog.RegisterLevel(VERBOSE, "VERBOSE", "", nil)
+	defer xlog.UnregisterLevel(VERBOSE)
+
+	levels := xlog.Levels()
+	if levels[len(levels)-1] != VERBOSE || levels[len(levels)-2] != xlog.TRACE {
+		t.Fatal("VERBOSE must be after TRACE: ", levels)
+	}
+
+	buf := &bytes.Buffer{}
+	logging := xlog.NewLogging()
+	logging.SetOutput(buf)
+	logging.SetSeverityLevel(xlog.TRACE)
+	logger := xlog.NewFactory(logging).GetLogger()
+	logger.Log(VERBOSE, "verbose log")
+	logger.Log(xlog.TRACE, "trace log")
+	if strings.Contains(buf.String(), "verbose log") || !strings.Contains(buf.String(), "trace log") {
+		t.Fatal("expect trace log only, got: ", buf.String())
+	}
+	logging.SetSeverityLevel(VERBOSE)
+	logger.Log(VERBOSE, "verbose log")
+	if !strings.Contains(buf.String(), "[VERBOSE]") {
+		t.Fatal("expect verbose log, got: ", buf.String())
+	}
+
+	xlog.UnregisterLevel(VERBOSE)
+	xlog.UnregisterLevel(xlog.INFO)
+	if VERBOSE.String() == "VERBOSE" || xlog.INFO.String() != "INFO" {
+		t.Fatal("unregister failed: ", VERBOSE, xlog.INFO)
+	}
+}
+
+func TestRegisterLevelConcurrent(t *testing.T) {
+	logging := xlog.NewLogging()
+	logging.SetOutput(ioutil.Discard)
+	logging.SetSeverityLevel(xlog.TRACE)
+	wg := sync.WaitGroup{}
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			lv := xlog.Level(-10 - i)
+			xlog.RegisterLevel(lv, "CUSTOM"+strconv.Itoa(i), xlog.ForeBlue, nil)
+			xlog.UnregisterLevel(lv)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			logging.Logln(xlog.INFO, 0, nil, xlog.INFO.String())
+			xlog.NewLogging()
+		}
+	}()
+	wg.Wait()
+}
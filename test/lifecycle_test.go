@@ -0,0 +1,228 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"bytes"
+	"context"
+	"github.com/xfali/xlog"
+	"github.com/xfali/xlog/writer"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type syncBuffer struct {
+	lock   sync.Mutex
+	buf    bytes.Buffer
+	closed bool
+}
+
+func (b *syncBuffer) Write(d []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.Write(d)
+}
+
+func (b *syncBuffer) Close() error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.closed = true
+	return nil
+}
+
+func (b *syncBuffer) String() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.String()
+}
+
+func TestLifecycleSync(t *testing.T) {
+	buf := &syncBuffer{}
+	w := writer.NewAsyncBufferWriter(buf, nil, writer.Config{
+		FlushSize:     1 << 20,
+		BufferSize:    1024,
+		FlushInterval: time.Hour,
+		Block:         true,
+	})
+	xlog.RegisterWriter(w)
+	xlog.RegisterWriter(w)
+	defer xlog.UnregisterWriter(w)
+
+	logging := xlog.NewLogging()
+	logging.SetOutput(w)
+	for i := 0; i < 100; i++ {
+		logging.Logln(xlog.INFO, 0, nil, "sync test")
+	}
+	if err := xlog.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(buf.String(), "sync test"); n != 100 {
+		t.Fatal("expect 100 lines after Sync, got: ", n)
+	}
+	if !xlog.UnregisterWriter(w) {
+		t.Fatal("expect registered")
+	}
+	if xlog.UnregisterWriter(w) {
+		t.Fatal("expect registered once")
+	}
+	w.Close()
+	if w.Sync() == nil {
+		t.Fatal("expect error after Close")
+	}
+}
+
+func TestLifecycleShutdown(t *testing.T) {
+	buf := &syncBuffer{}
+	w := writer.NewAsyncWriter(buf, buf.Close, 1024, true)
+	xlog.RegisterWriter(w)
+
+	logging := xlog.NewLogging()
+	logging.SetOutput(w)
+	for i := 0; i < 100; i++ {
+		logging.Logln(xlog.INFO, 0, nil, "shutdown test")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := xlog.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(buf.String(), "shutdown test"); n != 100 {
+		t.Fatal("expect 100 lines after Shutdown, got: ", n)
+	}
+	if !buf.closed {
+		t.Fatal("expect closed after Shutdown")
+	}
+	if xlog.UnregisterWriter(w) {
+		t.Fatal("expect unregistered after Shutdown")
+	}
+}
+
+func TestLifecycleFatal(t *testing.T) {
+	buf := &syncBuffer{}
+	w := writer.NewAsyncBufferWriter(buf, nil, writer.Config{
+		FlushSize:     1 << 20,
+		BufferSize:    1024,
+		FlushInterval: time.Hour,
+		Block:         true,
+	})
+	xlog.RegisterWriter(w)
+	defer func() {
+		xlog.UnregisterWriter(w)
+		w.Close()
+	}()
+
+	exited := false
+	logging := xlog.NewLogging(xlog.SetFatalNoTrace(true), xlog.SetExitFunc(func(code int) {
+		if !strings.Contains(buf.String(), "fatal test") {
+			t.Fatal("expect synced before exit, got: ", buf.String())
+		}
+		exited = true
+	}))
+	logging.SetOutput(w)
+	logging.Logln(xlog.FATAL, 0, nil, "fatal test")
+	if !exited {
+		t.Fatal("expect exit")
+	}
+}
+
+func TestLifecycleOutput(t *testing.T) {
+	buf := &syncBuffer{}
+	w := writer.NewAsyncWriter(buf, nil, 1024, true)
+	defer w.Close()
+
+	logging := xlog.NewLogging()
+	logging.SetOutputBySeverity(xlog.ERROR, w)
+	if !xlog.UnregisterWriter(w) {
+		t.Fatal("expect registered by SetOutputBySeverity")
+	}
+	logging.SetOutput(&bytes.Buffer{})
+	if xlog.UnregisterWriter(&bytes.Buffer{}) {
+		t.Fatal("writer without Sync or Close must not be registered")
+	}
+
+	// 控制台不支持同步，不应返回错误
+	console := writer.NewAsyncBufferWriter(os.Stdout, nil, writer.Config{})
+	defer console.Close()
+	logging.SetOutput(console)
+	defer xlog.UnregisterWriter(console)
+	logging.Logln(xlog.INFO, 0, nil, "console sync")
+	if err := xlog.Sync(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLifecycleReplaceOutput(t *testing.T) {
+	w1 := writer.NewAsyncWriter(ioutil.Discard, nil, 1024, true)
+	defer w1.Close()
+	w2 := writer.NewAsyncWriter(ioutil.Discard, nil, 1024, true)
+	defer w2.Close()
+	w3 := writer.NewAsyncWriter(ioutil.Discard, nil, 1024, true)
+	defer w3.Close()
+
+	// 自动注册的Writer被所有使用的Logging替换后取消注册
+	logging := xlog.NewLogging()
+	logging.SetOutput(w1)
+	clone := logging.Clone()
+	logging.SetOutput(w2)
+	clone.SetOutput(w2)
+	if xlog.UnregisterWriter(w1) {
+		t.Fatal("replaced writer must be unregistered")
+	}
+
+	// 通过RegisterWriter注册的Writer被替换后保持注册
+	xlog.RegisterWriter(w3)
+	logging.SetOutput(w3)
+	logging.SetOutput(w2)
+	if !xlog.UnregisterWriter(w3) {
+		t.Fatal("explicitly registered writer must stay registered")
+	}
+	if !xlog.UnregisterWriter(w2) {
+		t.Fatal("expect registered by SetOutput")
+	}
+}
+
+func TestLifecycleCrash(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xlog_crash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "crash.log")
+	ring := writer.NewRing(10, 0)
+	ring.SetCrashDumpFile(path)
+	xlog.RegisterWriter(ring)
+	defer xlog.UnregisterWriter(ring)
+
+	var panicked, exited bool
+	logging := xlog.NewLogging(xlog.SetFatalNoTrace(true), xlog.SetPanicFunc(func(v interface{}) {
+		panicked = true
+	}), xlog.SetExitFunc(func(code int) {
+		exited = true
+	}))
+	logging.SetOutput(io.MultiWriter(&bytes.Buffer{}, ring))
+	logging.Logln(xlog.INFO, 0, nil, "before crash")
+	logging.Logln(xlog.PANIC, 0, nil, "panic test")
+	// panic可能被recover，不输出
+	if _, err := os.Stat(path); !panicked || !os.IsNotExist(err) {
+		t.Fatal("expect no dump on panic: ", err)
+	}
+
+	logging.Logln(xlog.FATAL, 0, nil, "fatal test")
+	d, err := ioutil.ReadFile(path)
+	if err != nil || !exited {
+		t.Fatal("expect dump on fatal: ", err)
+	}
+	if !strings.Contains(string(d), "before crash") || !strings.Contains(string(d), "panic test") || !strings.Contains(string(d), "fatal test") {
+		t.Fatal("dump not match: ", string(d))
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"bytes"
+	"github.com/xfali/xlog"
+	"os"
+	"testing"
+)
+
+func TestLoggingConfig(t *testing.T) {
+	buf := &bytes.Buffer{}
+	formatter := &xlog.JsonFormatter{}
+	logging := xlog.NewLogging(xlog.SetColorFlag(xlog.ForceColor), xlog.SetCallerFlag(xlog.CallerShortFile),
+		xlog.SetStackTraceLevel(xlog.ERROR), xlog.SetStaticFields(xlog.String("env", "test")),
+		xlog.SetLevelsByName(map[string]xlog.Level{"db": xlog.DEBUG}))
+	logging.SetSeverityLevel(xlog.WARN)
+	logging.SetFormatter(formatter)
+	logging.SetOutputBySeverity(xlog.ERROR, buf)
+
+	if logging.GetFormatter() != formatter || logging.GetSeverityLevel() != xlog.WARN {
+		t.Fatal("formatter or level not match")
+	}
+
+	conf := logging.Config()
+	if conf.Level != xlog.WARN || conf.Levels["db"] != xlog.DEBUG || conf.Levels[xlog.RootLoggerName] != xlog.WARN {
+		t.Fatal("levels not match: ", conf.Levels)
+	}
+	if conf.Formatter != formatter || conf.ColorFlag != xlog.ForceColor || conf.CallerFlag != xlog.CallerShortFile {
+		t.Fatal("formatter or flags not match: ", conf)
+	}
+	if !conf.WithStack || conf.StackLevel != xlog.ERROR || len(conf.StaticFields) != 1 {
+		t.Fatal("stack or static fields not match: ", conf)
+	}
+	if conf.Writers[xlog.ERROR] != buf || conf.Writers[xlog.INFO] != os.Stdout {
+		t.Fatal("writers not match: ", conf.Writers)
+	}
+	if conf.TimeFormatter == nil || conf.CallerFormatter == nil || conf.ExitFunc == nil || conf.PanicFunc == nil {
+		t.Fatal("formatters must not be nil")
+	}
+
+	// 修改快照不影响Logging
+	conf.Levels["db"] = xlog.ERROR
+	conf.Writers[xlog.INFO] = buf
+	if !logging.IsEnabledByName("db", xlog.DEBUG) || logging.GetOutputBySeverity(xlog.INFO) != os.Stdout {
+		t.Fatal("snapshot must not change logging")
+	}
+}
+
+func TestLoggingConfigWrapper(t *testing.T) {
+	logging := xlog.NewLogging()
+	logging.SetSeverityLevel(xlog.ERROR)
+	wrappers := []xlog.Logging{
+		xlog.NewHookLevelLogging(logging, func(level xlog.Level) xlog.Level { return level }),
+		xlog.NewSamplingLogging(logging),
+		xlog.NewDedupLogging(logging),
+		xlog.NewFingersCrossedLogging(logging),
+	}
+	for _, w := range wrappers {
+		if w.GetSeverityLevel() != xlog.ERROR || w.Config().Level != xlog.ERROR || w.GetFormatter() != nil {
+			t.Fatalf("%T config not match", w)
+		}
+	}
+}
@@ -6,9 +6,13 @@
 package test
 
 import (
+	"bytes"
+	"errors"
 	"github.com/xfali/xlog"
+	"io"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"testing"
 	"time"
@@ -85,6 +89,22 @@ func TestLoggingWithCloneAndFormat(t *testing.T) {
 	l.Logln(xlog.ERROR, 0, xlog.NewKeyValues("int", 1, "string", "2"), "Clone ERROR", " test")
 }
 
+func TestLoggingCloneFuncs(t *testing.T) {
+	var panicked, exited bool
+	l := xlog.NewLogging(xlog.SetFatalNoTrace(true), xlog.SetPanicFunc(func(v interface{}) {
+		panicked = true
+	}), xlog.SetExitFunc(func(code int) {
+		exited = true
+	}))
+	l.SetOutput(&bytes.Buffer{})
+	l = l.Clone()
+	l.Logln(xlog.PANIC, 0, nil, "clone panic")
+	l.Logln(xlog.FATAL, 0, nil, "clone fatal")
+	if !panicked || !exited {
+		t.Fatal("clone must keep panic and exit func")
+	}
+}
+
 func TestLoggingFatal(t *testing.T) {
 	go func() {
 		time.Sleep(3 * time.Second)
@@ -130,3 +150,129 @@ func TestLoggingHook(t *testing.T) {
 func b(logging xlog.Logging) {
 	logging.Logln(xlog.INFO, 0, nil, "test")
 }
+
+func TestLoggingSampling(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := xlog.NewLogging()
+	l.SetOutput(buf)
+	sampler := xlog.NewSamplingLogging(l, xlog.SetSamplingConfig(xlog.SamplingConfig{
+		Initial:    2,
+		Thereafter: 3,
+		Tick:       time.Minute,
+	}), xlog.SetSamplingByLevel(xlog.WARN, xlog.SamplingConfig{}))
+	for i := 0; i < 10; i++ {
+		sampler.Logf(xlog.ERROR, 0, nil, "dependency down: %d", i)
+		sampler.Logln(xlog.WARN, 0, nil, "not sampled")
+	}
+	// 1, 2, 5, 8
+	if n := strings.Count(buf.String(), "dependency down"); n != 4 {
+		t.Fatal("expect 4 sampled lines, got ", n, buf.String())
+	}
+	if n := strings.Count(buf.String(), "not sampled"); n != 10 {
+		t.Fatal("expect 10 lines, got ", n)
+	}
+	if sampler.Dropped() != 6 {
+		t.Fatal("expect 6 dropped, got ", sampler.Dropped())
+	}
+
+	buf.Reset()
+	l.SetFormatter(&xlog.TextFormatter{})
+	sampler.Logf(xlog.ERROR, 0, nil, "dependency down: %d", 10)
+	sampler.Logf(xlog.ERROR, 0, nil, "dependency down: %d", 11)
+	t.Log(buf.String())
+	if !strings.Contains(buf.String(), xlog.KeySampledDropped+"=2") {
+		t.Fatal("expect dropped count, got ", buf.String())
+	}
+}
+
+func TestLoggingSamplingArgs(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := xlog.NewLogging()
+	l.SetOutput(buf)
+	sampler := xlog.NewSamplingLogging(l, xlog.SetSamplingConfig(xlog.SamplingConfig{
+		Initial:    1,
+		Thereafter: 0,
+		Tick:       time.Minute,
+	}))
+	evaluated := 0
+	lazy := xlog.Lazy(func() interface{} {
+		evaluated++
+		return "state"
+	})
+	for i := 0; i < 5; i++ {
+		// 第一个参数相同时为相同的日志
+		sampler.Logln(xlog.INFO, 0, nil, "request failed", i)
+		// 同一调用位置为相同的日志，被丢弃时不求值
+		sampler.Log(xlog.INFO, 0, nil, lazy)
+	}
+	if n := strings.Count(buf.String(), "request failed"); n != 1 {
+		t.Fatal("expect 1 line, got ", n, buf.String())
+	}
+	if evaluated != 1 || sampler.Dropped() != 8 {
+		t.Fatal("lazy value must be evaluated only on output: ", evaluated, sampler.Dropped())
+	}
+}
+
+func TestLoggingSamplingPanic(t *testing.T) {
+	l := xlog.NewLogging()
+	l.SetOutput(&bytes.Buffer{})
+	sampler := xlog.NewSamplingLogging(l, xlog.SetSamplingConfig(xlog.SamplingConfig{
+		Initial:    1,
+		Thereafter: 0,
+		Tick:       time.Minute,
+	}))
+	panics := 0
+	for i := 0; i < 3; i++ {
+		func() {
+			defer func() {
+				if recover() != nil {
+					panics++
+				}
+			}()
+			sampler.Logln(xlog.PANIC, 0, nil, "panic")
+		}()
+	}
+	if panics != 3 || sampler.Dropped() != 0 {
+		t.Fatal("PANIC must not be sampled: ", panics, sampler.Dropped())
+	}
+}
+
+type failedWriter struct{}
+
+func (w failedWriter) Write(d []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+type failedFormatter struct{}
+
+func (f failedFormatter) Format(writer io.Writer, keyValues xlog.KeyValues) error {
+	return errors.New("format failed")
+}
+
+func TestLoggingErrorHandler(t *testing.T) {
+	var errs []error
+	logging := xlog.NewLogging(xlog.SetErrorHandler(func(err error) {
+		errs = append(errs, err)
+	}))
+	logging.SetOutput(failedWriter{})
+	logger := xlog.NewFactory(logging).GetLogger()
+	logger.Infoln("write failed")
+	logging.SetFormatter(&xlog.JsonFormatter{})
+	logger.Warnln("write failed")
+
+	logging.SetOutput(&bytes.Buffer{})
+	logging.SetFormatter(failedFormatter{})
+	logger.Errorln("format failed")
+
+	stats := logging.GetErrorStats()
+	if stats.WriteErrors != 2 || stats.FormatErrors != 1 || len(errs) != 3 {
+		t.Fatal("stats not match: ", stats, errs)
+	}
+	var le *xlog.LoggingError
+	if !errors.As(errs[1], &le) || le.Op != xlog.ErrorOpWrite || le.Level != xlog.WARN {
+		t.Fatal("error not match: ", errs[1])
+	}
+	if !errors.As(errs[2], &le) || le.Op != xlog.ErrorOpFormat || le.Level != xlog.ERROR {
+		t.Fatal("error not match: ", errs[2])
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/xfali/xlog"
+	"os"
+	"testing"
+)
+
+func TestProcessFields(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logging := xlog.NewLogging(xlog.SetHostname(""), xlog.SetPid("pid"), xlog.SetAppName(""),
+		xlog.SetBuildVersion(""), xlog.SetGoroutineID(""),
+		xlog.SetStaticFields(xlog.String("env", "test"), xlog.String("zone", "a")))
+	logging.SetOutput(buf)
+	logging.SetFormatter(&xlog.JsonFormatter{})
+	logger := xlog.NewFactory(logging).GetLogger("svc").WithFields("zone", "b")
+	logger.Infoln("process fields")
+
+	ret := map[string]interface{}{}
+	if err := json.Unmarshal(buf.Bytes(), &ret); err != nil {
+		t.Fatal(err, buf.String())
+	}
+	hostname, _ := os.Hostname()
+	if ret[xlog.KeyHostname] != hostname || ret["pid"] != float64(os.Getpid()) || ret[xlog.KeyApp] == "" ||
+		ret[xlog.KeyVersion] == nil || ret["env"] != "test" {
+		t.Fatal("process fields not match: ", buf.String())
+	}
+	if ret["zone"] != "b" || ret[xlog.KeyName] != "svc" {
+		t.Fatal("logger fields must override static fields: ", buf.String())
+	}
+	if id, ok := ret[xlog.KeyGoroutine].(float64); !ok || id <= 0 {
+		t.Fatal("goroutine id not match: ", buf.String())
+	}
+
+	buf.Reset()
+	clone := logging.Clone()
+	clone.SetOutput(buf)
+	clone.SetFormatter(&xlog.JsonFormatter{})
+	clone.Logln(xlog.INFO, 0, nil, "clone")
+	ret = map[string]interface{}{}
+	if err := json.Unmarshal(buf.Bytes(), &ret); err != nil || ret["env"] != "test" {
+		t.Fatal("clone must keep static fields: ", buf.String())
+	}
+}
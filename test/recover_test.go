@@ -0,0 +1,88 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/xfali/xlog"
+	"strings"
+	"testing"
+	"time"
+)
+
+type chanWriter chan string
+
+func (w chanWriter) Write(d []byte) (int, error) {
+	w <- string(d)
+	return len(d), nil
+}
+
+func TestRecover(t *testing.T) {
+	buf := &bytes.Buffer{}
+	exitCode := 0
+	logging := xlog.NewLogging(xlog.SetFatalNoTrace(true), xlog.SetExitFunc(func(code int) {
+		exitCode = code
+	}))
+	logging.SetOutput(buf)
+	logging.SetFormatter(&xlog.JsonFormatter{})
+	logger := xlog.NewFactory(logging).GetLogger("worker").WithFields("job", "sync")
+
+	func() {
+		defer logger.Recover()
+		panic("boom")
+	}()
+	ret := struct {
+		Name  string       `json:"LogName"`
+		Job   string       `json:"job"`
+		Level string       `json:"LogLevel"`
+		Panic string       `json:"LogPanic"`
+		Stack []xlog.Frame `json:"LogStack"`
+	}{}
+	if err := json.Unmarshal(buf.Bytes(), &ret); err != nil {
+		t.Fatal(err, buf.String())
+	}
+	if ret.Name != "worker" || ret.Job != "sync" || ret.Level != "ERROR" || ret.Panic != "boom" {
+		t.Fatal("recover log not match: ", buf.String())
+	}
+	if !strings.Contains(buf.String(), "TestRecover.func") {
+		t.Fatal("stack must contain panic function: ", buf.String())
+	}
+
+	var repanic interface{}
+	func() {
+		defer func() {
+			repanic = recover()
+		}()
+		defer logger.Recover(xlog.SetRecoverMode(xlog.RecoverRepanic), xlog.SetRecoverLevel(xlog.WARN))
+		panic("again")
+	}()
+	if repanic != "again" || !strings.Contains(buf.String(), `"LogLevel":"WARN"`) {
+		t.Fatal("expect repanic, got: ", repanic, buf.String())
+	}
+
+	func() {
+		defer logger.Recover(xlog.SetRecoverMode(xlog.RecoverExit))
+		panic("exit")
+	}()
+	if exitCode != -1 || !strings.Contains(buf.String(), `"LogLevel":"FATAL"`) {
+		t.Fatal("expect exit, got: ", exitCode, buf.String())
+	}
+
+	ch := make(chanWriter, 1)
+	logging.SetOutput(ch)
+	xlog.GoWith(logger, func() {
+		panic("goroutine")
+	})
+	select {
+	case s := <-ch:
+		if !strings.Contains(s, "panic: goroutine") {
+			t.Fatal("goroutine log not match: ", s)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("goroutine panic not logged")
+	}
+}
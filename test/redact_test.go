@@ -0,0 +1,91 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/xfali/xlog"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+type secretCard string
+
+func (c secretCard) Redact() interface{} {
+	return "card:" + string(c[len(c)-4:])
+}
+
+func TestRedaction(t *testing.T) {
+	hash := xlog.MaskHash([]byte("key"))
+	r := xlog.NewRedaction(
+		xlog.SetRedactKeys(xlog.MaskFull(), xlog.SensitiveKeys...),
+		xlog.SetRedactKeys(hash, "user"),
+		xlog.SetRedactPattern(regexp.MustCompile(`\d{16}`), xlog.MaskKeepLast(4)))
+	buf := &bytes.Buffer{}
+	logging := xlog.NewLogging(xlog.SetRedaction(r))
+	logging.SetOutput(buf)
+	logging.SetFormatter(&xlog.JsonFormatter{})
+	logger := xlog.NewFactory(logging).GetLogger()
+
+	fields := xlog.NewFields(xlog.String("Password", "123456"), xlog.String("user", "alice"))
+	logger.With(*fields...).With(
+		xlog.Any("card", secretCard("6222020200001234")),
+		xlog.String("remark", "pay by 6222020200005678")).Infof("token: %s", "6222020200009012")
+
+	ret := map[string]interface{}{}
+	if err := json.Unmarshal(buf.Bytes(), &ret); err != nil {
+		t.Fatal(err, buf.String())
+	}
+	if ret["Password"] != "******" || ret["user"] != hash("alice") || ret["card"] != "card:1234" {
+		t.Fatal("redaction not match: ", buf.String())
+	}
+	if ret["remark"] != "pay by ************5678" || !strings.Contains(ret[xlog.KeyContent].(string), "************9012") {
+		t.Fatal("pattern not match: ", buf.String())
+	}
+	if fields.Get("Password") != "123456" {
+		t.Fatal("origin fields must not be changed")
+	}
+	if hash("alice") == hash("bob") || !strings.HasPrefix(hash("alice"), "hmac:") {
+		t.Fatal("hash not match")
+	}
+
+	buf.Reset()
+	logging.SetFormatter(nil)
+	logger.WithFields("Authorization", "Bearer abc").Infoln("text format")
+	if strings.Contains(buf.String(), "Bearer") || !strings.Contains(buf.String(), "******") {
+		t.Fatal("text format not redacted: ", buf.String())
+	}
+	if xlog.MaskKeepLast(4)("abc") != "***" {
+		t.Fatal("keep last not match")
+	}
+}
+
+func TestRedactionError(t *testing.T) {
+	r := xlog.NewRedaction(xlog.SetRedactPattern(regexp.MustCompile(`\d{16}`), xlog.MaskKeepLast(4)))
+	buf := &bytes.Buffer{}
+	logging := xlog.NewLogging(xlog.SetRedaction(r), xlog.SetExtractErrors(true))
+	logging.SetOutput(buf)
+	logging.SetFormatter(&xlog.JsonFormatter{})
+	logger := xlog.NewFactory(logging).GetLogger()
+
+	err := fmt.Errorf("charge failed: %w", errors.New("invalid card 6222020200001234"))
+	logger.Errorln("pay", err)
+	ret := struct {
+		Err    string   `json:"LogError"`
+		Causes []string `json:"LogErrorCauses"`
+	}{}
+	if err := json.Unmarshal(buf.Bytes(), &ret); err != nil {
+		t.Fatal(err, buf.String())
+	}
+	if strings.Contains(buf.String(), "6222020200001234") || ret.Err != "charge failed: invalid card ************1234" ||
+		len(ret.Causes) != 1 || !strings.HasSuffix(ret.Causes[0], "invalid card ************1234") {
+		t.Fatal("error not redacted: ", buf.String())
+	}
+}
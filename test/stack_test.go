@@ -0,0 +1,130 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/xfali/xlog"
+	"io"
+	"strings"
+	"testing"
+)
+
+type multiError []error
+
+func (e multiError) Error() string {
+	return "multi error"
+}
+
+func (e multiError) Unwrap() []error {
+	return e
+}
+
+func TestStackTrace(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logging := xlog.NewLogging(xlog.SetStackTraceLevel(xlog.ERROR))
+	logging.SetOutput(buf)
+	logging.SetFormatter(&xlog.JsonFormatter{})
+	logger := xlog.NewFactory(logging).GetLogger()
+
+	logger.Infoln("no stack")
+	if strings.Contains(buf.String(), xlog.KeyStack) {
+		t.Fatal("info must not have stack: ", buf.String())
+	}
+
+	buf.Reset()
+	logger.Errorln("with stack")
+	ret := struct {
+		Stack []xlog.Frame `json:"LogStack"`
+	}{}
+	if err := json.Unmarshal(buf.Bytes(), &ret); err != nil {
+		t.Fatal(err, buf.String())
+	}
+	if len(ret.Stack) == 0 || !strings.HasSuffix(ret.Stack[0].Function, "TestStackTrace") || ret.Stack[0].Line == 0 {
+		t.Fatal("stack not match: ", buf.String())
+	}
+
+	buf.Reset()
+	logging.SetFormatter(nil)
+	logger.Errorln("text stack")
+	if !strings.Contains(buf.String(), "test.TestStackTrace\n\t") {
+		t.Fatal("text stack not match: ", buf.String())
+	}
+}
+
+func TestErrorChain(t *testing.T) {
+	wrapped := fmt.Errorf("read config: %w", io.EOF)
+	joined := multiError{wrapped, errors.New("closed")}
+	chain := xlog.ErrorChain(joined)
+	if len(chain) != 3 || chain[0] != wrapped || chain[1] != io.EOF || chain[2].Error() != "closed" {
+		t.Fatal("chain not match: ", chain)
+	}
+
+	buf := &bytes.Buffer{}
+	logging := xlog.NewLogging(xlog.SetExtractErrors(true))
+	logging.SetOutput(buf)
+	logging.SetFormatter(&xlog.JsonFormatter{})
+	logger := xlog.NewFactory(logging).GetLogger()
+	logger.Errorln("load failed", wrapped)
+
+	ret := struct {
+		Err    string   `json:"LogError"`
+		Causes []string `json:"LogErrorCauses"`
+	}{}
+	if err := json.Unmarshal(buf.Bytes(), &ret); err != nil {
+		t.Fatal(err, buf.String())
+	}
+	if ret.Err != "read config: EOF" || len(ret.Causes) != 1 || ret.Causes[0] != "*errors.errorString: EOF" {
+		t.Fatal("json error not match: ", buf.String())
+	}
+
+	buf.Reset()
+	logger.With(xlog.Err(joined)).Errorln("joined")
+	ret.Causes = nil
+	if err := json.Unmarshal(buf.Bytes(), &ret); err != nil {
+		t.Fatal(err, buf.String())
+	}
+	if ret.Err != "multi error" || len(ret.Causes) != 3 || ret.Causes[0] != "*fmt.wrapError: read config: EOF" ||
+		ret.Causes[1] != "*errors.errorString: EOF" || ret.Causes[2] != "*errors.errorString: closed" {
+		t.Fatal("json causes not match: ", buf.String())
+	}
+
+	buf.Reset()
+	logging.SetFormatter(&xlog.TextFormatter{})
+	logger.Errorln("text", wrapped)
+	if !strings.Contains(buf.String(), "LogError=read config: EOF ") || !strings.Contains(buf.String(), xlog.KeyErrorCauses+"=") {
+		t.Fatal("text error not match: ", buf.String())
+	}
+
+	// 所有error类型的附加信息都输出错误链
+	buf.Reset()
+	logging.SetFormatter(&xlog.JsonFormatter{})
+	logger.With(xlog.NamedErr("cause", wrapped)).Errorln("named", joined)
+	named := struct {
+		Err         string   `json:"LogError"`
+		Causes      []string `json:"LogErrorCauses"`
+		NamedCauses []string `json:"causeCauses"`
+	}{}
+	if err := json.Unmarshal(buf.Bytes(), &named); err != nil {
+		t.Fatal(err, buf.String())
+	}
+	if named.Err != "multi error" || len(named.Causes) != 3 || len(named.NamedCauses) != 1 || named.NamedCauses[0] != "*errors.errorString: EOF" {
+		t.Fatal("named error causes not match: ", buf.String())
+	}
+
+	// 未开启时error只输出错误信息
+	buf.Reset()
+	logging = xlog.NewLogging()
+	logging.SetOutput(buf)
+	logging.SetFormatter(&xlog.JsonFormatter{})
+	xlog.NewFactory(logging).GetLogger().With(xlog.Err(joined)).Errorln("plain")
+	if !strings.Contains(buf.String(), `"LogError":"multi error"`) || strings.Contains(buf.String(), xlog.KeyErrorCauses) {
+		t.Fatal("plain error not match: ", buf.String())
+	}
+}
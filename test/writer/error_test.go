@@ -0,0 +1,52 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package writer
+
+import (
+	"errors"
+	"github.com/xfali/xlog/writer"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type failedWriter struct{}
+
+func (w failedWriter) Write(d []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestWriterErrorHandler(t *testing.T) {
+	var handled int32
+	h := func(err error) {
+		atomic.AddInt32(&handled, 1)
+	}
+
+	aw := writer.NewAsyncWriter(failedWriter{}, nil, 10, true)
+	aw.SetErrorHandler(h)
+	aw.Write([]byte("async log\n"))
+	aw.Write([]byte("async log\n"))
+	time.Sleep(50 * time.Millisecond)
+	aw.Close()
+	if aw.Errors() != 2 {
+		t.Fatal("expect 2 errors, got: ", aw.Errors())
+	}
+
+	bw := writer.NewAsyncBufferWriter(failedWriter{}, nil, writer.Config{
+		FlushSize:     1,
+		FlushInterval: time.Hour,
+		Block:         true,
+		ErrorHandler:  h,
+	})
+	bw.Write([]byte("buffer log\n"))
+	bw.Close()
+	if bw.Errors() == 0 {
+		t.Fatal("expect errors")
+	}
+	if atomic.LoadInt32(&handled) != int32(aw.Errors()+bw.Errors()) {
+		t.Fatal("handled not match: ", handled, aw.Errors(), bw.Errors())
+	}
+}
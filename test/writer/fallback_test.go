@@ -0,0 +1,90 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package writer
+
+import (
+	"bytes"
+	"errors"
+	"github.com/xfali/xlog/writer"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+type switchWriter struct {
+	fail bool
+	buf  bytes.Buffer
+}
+
+func (w *switchWriter) Write(d []byte) (int, error) {
+	if w.fail {
+		return 0, errors.New("write failed")
+	}
+	return w.buf.Write(d)
+}
+
+func TestFallback(t *testing.T) {
+	primary := &switchWriter{}
+	secondary := &bytes.Buffer{}
+	w := writer.Fallback(primary, secondary)
+	w.SetProbeInterval(20 * time.Millisecond)
+	var errs []error
+	w.SetErrorHandler(func(err error) {
+		errs = append(errs, err)
+	})
+
+	w.Write([]byte("1"))
+	primary.fail = true
+	w.Write([]byte("2"))
+	w.Write([]byte("3"))
+	if primary.buf.String() != "1" || secondary.String() != "23" || w.Current() != 1 {
+		t.Fatal("expect fallback, got: ", primary.buf.String(), secondary.String(), w.Current())
+	}
+	if len(errs) != 1 || w.Errors() != 1 {
+		t.Fatal("primary must not be probed before interval: ", errs)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	w.Write([]byte("4"))
+	if w.Errors() != 2 || secondary.String() != "234" {
+		t.Fatal("expect probe failed, got: ", w.Errors(), secondary.String())
+	}
+
+	primary.fail = false
+	w.Write([]byte("5"))
+	time.Sleep(30 * time.Millisecond)
+	w.Write([]byte("6"))
+	w.Write([]byte("7"))
+	if primary.buf.String() != "167" || secondary.String() != "2345" || w.Current() != 0 {
+		t.Fatal("expect recovered, got: ", primary.buf.String(), secondary.String(), w.Current())
+	}
+
+	primary.fail = true
+	w2 := writer.Fallback(primary, &switchWriter{fail: true})
+	if _, err := w2.Write([]byte("8")); err == nil {
+		t.Fatal("expect error")
+	}
+}
+
+func TestFallbackClose(t *testing.T) {
+	f, err := ioutil.TempFile("", "fallback")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+
+	w := writer.Fallback(f, os.Stderr)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Stat(); err == nil {
+		t.Fatal("expect primary closed")
+	}
+	if _, err := os.Stderr.Stat(); err != nil {
+		t.Fatal("os.Stderr must not be closed: ", err)
+	}
+}
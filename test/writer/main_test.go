@@ -0,0 +1,19 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package writer
+
+import (
+	"os"
+	"testing"
+)
+
+// 测试输出的日志文件保存在target目录下，该目录不纳入版本管理
+func TestMain(m *testing.M) {
+	if err := os.MkdirAll("target", 0755); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
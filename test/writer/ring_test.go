@@ -0,0 +1,121 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package writer
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/xfali/xlog"
+	"github.com/xfali/xlog/writer"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRing(t *testing.T) {
+	r := writer.NewRing(3, 0)
+	for i := 0; i < 5; i++ {
+		r.Write([]byte(fmt.Sprintf("log %d\n", i)))
+	}
+	if r.Len() != 3 {
+		t.Fatal("expect 3 entries, got: ", r.Len())
+	}
+	buf := &bytes.Buffer{}
+	if err := r.Dump(buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "log 2\nlog 3\nlog 4\n" {
+		t.Fatal("expect last 3 entries, got: ", buf.String())
+	}
+
+	r = writer.NewRing(0, 14)
+	for i := 0; i < 100; i++ {
+		r.Write([]byte(fmt.Sprintf("log %d\n", i)))
+	}
+	ss := r.Snapshot()
+	if len(ss) != 2 || string(ss[0].Data) != "log 98\n" || r.Size() != 14 {
+		t.Fatal("expect last 14 bytes, got: ", len(ss), r.Size())
+	}
+
+	// 不能同时不限制，使用默认条数
+	r2 := writer.NewRing(0, 0)
+	for i := 0; i < writer.DefaultRingMaxEntries+1; i++ {
+		r2.Write([]byte("log\n"))
+	}
+	if r2.Len() != writer.DefaultRingMaxEntries {
+		t.Fatal("expect default limit, got: ", r2.Len())
+	}
+
+	dir, err := ioutil.TempDir("", "xlog_ring")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "dump.log")
+	if err := r.DumpFile(path); err != nil {
+		t.Fatal(err)
+	}
+	d, _ := ioutil.ReadFile(path)
+	if string(d) != "log 98\nlog 99\n" {
+		t.Fatal("dump file not match: ", string(d))
+	}
+}
+
+func TestRingQuery(t *testing.T) {
+	r := writer.NewRing(1000, 0)
+	logging := xlog.NewLogging()
+	logging.SetFormatter(&xlog.JsonFormatter{})
+	logging.SetSeverityLevel(xlog.DEBUG)
+	logging.SetOutput(r)
+	logger := xlog.NewFactory(logging).GetLogger("db")
+	logger.Debugln("connect")
+	logger.WithName("pool").Warnln("pool exhausted")
+	xlog.NewFactory(logging).GetLogger("dbx").Warnln("other")
+
+	ret := r.Query(writer.RingQuery{Levels: []string{"warn"}})
+	if len(ret) != 2 {
+		t.Fatal("expect 2 WARN entries, got: ", len(ret))
+	}
+	ret = r.Query(writer.RingQuery{Names: []string{"db"}})
+	if len(ret) != 2 || !bytes.Contains(ret[1].Data, []byte("pool exhausted")) {
+		t.Fatal("expect db and db.pool entries, got: ", len(ret))
+	}
+	ret = r.Query(writer.RingQuery{Names: []string{"db"}, Levels: []string{"DEBUG"}, Contains: "connect"})
+	if len(ret) != 1 {
+		t.Fatal("expect 1 entry, got: ", len(ret))
+	}
+	r.Reset()
+	if r.Len() != 0 || len(r.Snapshot()) != 0 {
+		t.Fatal("expect empty after Reset")
+	}
+}
+
+func TestRingCrashDump(t *testing.T) {
+	ring := writer.NewRing(2, 0)
+	if err := ring.OnCrash(); err != nil {
+		t.Fatal("crash dump file not set, expect nil, got: ", err)
+	}
+	dir, err := ioutil.TempDir("", "xlog_ring")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "crash.log")
+	ring.SetCrashDumpFile(path)
+	ring.Write([]byte("1\n"))
+	ring.Write([]byte("2\n"))
+	ring.Write([]byte("3\n"))
+	if err := ring.OnCrash(); err != nil {
+		t.Fatal(err)
+	}
+	d, err := ioutil.ReadFile(path)
+	if err != nil || string(d) != "2\n3\n" {
+		t.Fatal("dump not match: ", string(d), err)
+	}
+	var _ xlog.CrashHandler = ring
+}
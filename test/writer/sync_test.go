@@ -0,0 +1,64 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package writer
+
+import (
+	"bytes"
+	"github.com/xfali/xlog/writer"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriterSync(t *testing.T) {
+	buf := &bytes.Buffer{}
+	aw := writer.NewAsyncWriter(buf, nil, 100, true)
+	for i := 0; i < 50; i++ {
+		aw.Write([]byte("async\n"))
+	}
+	if err := aw.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 50*len("async\n") {
+		t.Fatal("expect all synced, got: ", buf.Len())
+	}
+	aw.Close()
+
+	dir, err := ioutil.TempDir("", "xlog_sync")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "sync.log")
+	f := writer.NewBufferedRotateFileWriter(&writer.BufferedRotateFile{
+		Path:            path,
+		RotateFrequency: writer.RotateNone,
+	}, writer.Config{
+		FlushSize:     1 << 20,
+		BufferSize:    100,
+		FlushInterval: time.Hour,
+		Block:         true,
+	})
+	defer f.Close()
+	f.Write([]byte("buffered\n"))
+	if err := f.(writer.Syncer).Sync(); err != nil {
+		t.Fatal(err)
+	}
+	d, _ := ioutil.ReadFile(path)
+	if string(d) != "buffered\n" {
+		t.Fatal("expect synced to file, got: ", string(d))
+	}
+}
+
+func TestWriterSyncConsole(t *testing.T) {
+	for _, w := range []writer.Syncer{&writer.LockedWriter{W: os.Stdout}, &writer.LockedWriter{W: os.Stderr}} {
+		if err := w.Sync(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
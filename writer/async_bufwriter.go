@@ -20,9 +20,11 @@ const (
 )
 
 type AsyncBufferLogWriter struct {
+	errs      errorCounter
 	wait      sync.WaitGroup
-	stopChan  chan bool
-	logChan   chan []byte
+	stopChan  chan struct{}
+	logChan   chan *[]byte
+	syncChan  chan chan error
 	logBuffer bytes.Buffer
 	FlushSize int64
 	w         io.Writer
@@ -42,6 +44,9 @@ type Config struct {
 
 	// 如果为true，则当超出bufSize大小时Write方法阻塞，否则返回error
 	Block bool
+
+	// 写入失败的处理函数，为nil时仅计数
+	ErrorHandler ErrorHandler
 }
 
 var defaultConfig = Config{
@@ -69,12 +74,14 @@ func NewAsyncBufferWriter(w io.Writer, closer Closer, c ...Config) *AsyncBufferL
 	}
 
 	l := AsyncBufferLogWriter{
-		stopChan:  make(chan bool),
-		logChan:   make(chan []byte, conf.BufferSize),
+		stopChan:  make(chan struct{}),
+		logChan:   make(chan *[]byte, conf.BufferSize),
+		syncChan:  make(chan chan error),
 		FlushSize: conf.FlushSize,
 		w:         w,
 		block:     conf.Block,
 	}
+	l.errs.setHandler(conf.ErrorHandler)
 	l.wait.Add(1)
 	l.logBuffer.Grow(conf.BufferSize * 10)
 
@@ -83,9 +90,9 @@ func NewAsyncBufferWriter(w io.Writer, closer Closer, c ...Config) *AsyncBufferL
 		defer func() {
 			size := len(l.logChan)
 			for i := 0; i < size; i++ {
-				l.writeLog(<-l.logChan)
+				l.errs.handle(l.writeLog(<-l.logChan))
 			}
-			l.Flush()
+			l.errs.handle(l.Flush())
 			if closer != nil {
 				closer()
 			}
@@ -98,16 +105,26 @@ func NewAsyncBufferWriter(w io.Writer, closer Closer, c ...Config) *AsyncBufferL
 				return
 			case d, ok := <-l.logChan:
 				if ok {
-					l.writeLog(d)
+					l.errs.handle(l.writeLog(d))
 				}
 			case <-ticker.C:
-				l.Flush()
+				l.errs.handle(l.Flush())
+			case ch := <-l.syncChan:
+				size := len(l.logChan)
+				for i := 0; i < size; i++ {
+					l.errs.handle(l.writeLog(<-l.logChan))
+				}
+				err := l.Flush()
+				if err == nil {
+					err = syncWriter(l.w)
+				}
+				ch <- err
 			}
 			select {
 			case <-l.stopChan:
 				return
 			case <-ticker.C:
-				l.Flush()
+				l.errs.handle(l.Flush())
 			default:
 			}
 		}
@@ -118,8 +135,10 @@ func NewAsyncBufferWriter(w io.Writer, closer Closer, c ...Config) *AsyncBufferL
 func (w *AsyncBufferLogWriter) Flush() error {
 	d := w.logBuffer.Bytes()
 	if len(d) > 0 {
-		_, err := w.w.Write(d)
+		n, err := w.w.Write(d)
 		if err != nil {
+			// 丢弃已写入的部分，剩余部分下次刷新时重试
+			w.logBuffer.Next(n)
 			return err
 		}
 		w.logBuffer.Reset()
@@ -127,8 +146,9 @@ func (w *AsyncBufferLogWriter) Flush() error {
 	return nil
 }
 
-func (w *AsyncBufferLogWriter) writeLog(data []byte) error {
-	w.logBuffer.Write(data)
+func (w *AsyncBufferLogWriter) writeLog(data *[]byte) error {
+	w.logBuffer.Write(*data)
+	putBytes(data)
 
 	if int64(w.logBuffer.Len()) < w.FlushSize {
 		return nil
@@ -137,6 +157,21 @@ func (w *AsyncBufferLogWriter) writeLog(data []byte) error {
 	return w.Flush()
 }
 
+// 将调用之前已接收的数据写入底层Writer并同步（线程安全）
+func (w *AsyncBufferLogWriter) Sync() error {
+	return requestSync(w.syncChan, w.stopChan)
+}
+
+// 设置写入失败的处理函数（线程安全）
+func (w *AsyncBufferLogWriter) SetErrorHandler(h ErrorHandler) {
+	w.errs.setHandler(h)
+}
+
+// 获得写入失败的次数（线程安全）
+func (w *AsyncBufferLogWriter) Errors() uint64 {
+	return w.errs.errors()
+}
+
 func (w *AsyncBufferLogWriter) Close() error {
 	w.once.Do(func() {
 		close(w.stopChan)
@@ -149,20 +184,24 @@ func (w *AsyncBufferLogWriter) Write(data []byte) (n int, err error) {
 	if len(data) == 0 {
 		return 0, nil
 	}
+	d := copyBytes(data)
 	if w.block {
 		select {
-		case w.logChan <- data:
+		case w.logChan <- d:
 			return len(data), nil
 		case <-w.stopChan:
+			putBytes(d)
 			return 0, errors.New("writer is closed")
 		}
 	} else {
 		select {
-		case w.logChan <- data:
+		case w.logChan <- d:
 			return len(data), nil
 		case <-w.stopChan:
+			putBytes(d)
 			return 0, errors.New("writer is closed")
 		default:
+			putBytes(d)
 			return 0, errors.New("write log failed ")
 		}
 	}
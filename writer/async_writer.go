@@ -19,8 +19,10 @@ const (
 type Closer func() error
 
 type AsyncLogWriter struct {
+	errs     errorCounter
 	stopChan chan struct{}
-	logChan  chan []byte
+	logChan  chan *[]byte
+	syncChan chan chan error
 	w        io.Writer
 	block    bool
 	wait     sync.WaitGroup
@@ -30,16 +32,17 @@ type AsyncLogWriter struct {
 // 异步写的Writer，本身Write、Close方法线程安全，参数WriteCloser可以非线程安全
 // Param： w - 实际写入的Writer, bufSize - 接收的最大长度, block - 如果为true，则当超出bufSize大小时Write方法阻塞，否则返回error
 func NewAsyncWriter(w io.Writer, closer Closer, bufSize int, block bool) *AsyncLogWriter {
-	var logChan chan []byte
+	var logChan chan *[]byte
 	// Channel without buffer
 	if bufSize <= 0 {
-		logChan = make(chan []byte)
+		logChan = make(chan *[]byte)
 	} else {
-		logChan = make(chan []byte, bufSize)
+		logChan = make(chan *[]byte, bufSize)
 	}
 	l := AsyncLogWriter{
 		stopChan: make(chan struct{}),
 		logChan:  logChan,
+		syncChan: make(chan chan error),
 		w:        w,
 		block:    block,
 	}
@@ -53,21 +56,50 @@ func NewAsyncWriter(w io.Writer, closer Closer, bufSize int, block bool) *AsyncL
 		for {
 			select {
 			case <-l.stopChan:
+				l.drain()
 				return
 			case d, ok := <-l.logChan:
 				if ok {
 					l.writeLog(d)
 				}
+			case ch := <-l.syncChan:
+				l.drain()
+				ch <- syncWriter(l.w)
 			}
 		}
 	}()
 	return &l
 }
 
-func (w *AsyncLogWriter) writeLog(data []byte) {
+func (w *AsyncLogWriter) writeLog(data *[]byte) {
 	if w.w != nil {
-		w.w.Write(data)
+		_, err := w.w.Write(*data)
+		w.errs.handle(err)
 	}
+	putBytes(data)
+}
+
+// 写入已接收的数据
+func (w *AsyncLogWriter) drain() {
+	size := len(w.logChan)
+	for i := 0; i < size; i++ {
+		w.writeLog(<-w.logChan)
+	}
+}
+
+// 将调用之前已接收的数据写入底层Writer并同步（线程安全）
+func (w *AsyncLogWriter) Sync() error {
+	return requestSync(w.syncChan, w.stopChan)
+}
+
+// 设置写入失败的处理函数（线程安全）
+func (w *AsyncLogWriter) SetErrorHandler(h ErrorHandler) {
+	w.errs.setHandler(h)
+}
+
+// 获得写入失败的次数（线程安全）
+func (w *AsyncLogWriter) Errors() uint64 {
+	return w.errs.errors()
 }
 
 func (w *AsyncLogWriter) Close() error {
@@ -84,13 +116,15 @@ func (w *AsyncLogWriter) Write(data []byte) (n int, err error) {
 	}
 
 	if w.block {
-		w.logChan <- data
+		w.logChan <- copyBytes(data)
 		return len(data), nil
 	} else {
+		d := copyBytes(data)
 		select {
-		case w.logChan <- data:
+		case w.logChan <- d:
 			return len(data), nil
 		default:
+			putBytes(d)
 			return 0, errors.New("write log failed ")
 		}
 	}
@@ -39,6 +39,9 @@ func NewBufferedRotateFileWriter(f *BufferedRotateFile, config ...Config) io.Wri
 	}
 	err := f.Open(conf)
 	if err != nil {
+		if conf.ErrorHandler != nil {
+			conf.ErrorHandler(err)
+		}
 		return nil
 	}
 
@@ -61,7 +64,8 @@ type BufferedRotateFile struct {
 	rotateDuration time.Duration
 
 	stopChan chan struct{}
-	logChan  chan []byte
+	logChan  chan *[]byte
+	syncChan chan chan error
 	block    bool
 	wait     sync.WaitGroup
 	once     sync.Once
@@ -76,18 +80,22 @@ type BufferedRotateFile struct {
 
 	flushSize int64
 	buf       *bytes.Buffer
+
+	errs errorCounter
 }
 
 func (f *BufferedRotateFile) Open(conf Config) error {
-	var logChan chan []byte
+	var logChan chan *[]byte
 	// Channel without buffer
 	if conf.BufferSize <= 0 {
-		logChan = make(chan []byte)
+		logChan = make(chan *[]byte)
 	} else {
-		logChan = make(chan []byte, conf.BufferSize)
+		logChan = make(chan *[]byte, conf.BufferSize)
 	}
 	f.block = conf.Block
+	f.errs.setHandler(conf.ErrorHandler)
 	f.logChan = logChan
+	f.syncChan = make(chan chan error)
 	f.stopChan = make(chan struct{})
 
 	if f.MaxFileSize == 0 {
@@ -134,9 +142,11 @@ func (f *BufferedRotateFile) Open(conf Config) error {
 			defer func() {
 				size := len(f.logChan)
 				for i := 0; i < size; i++ {
-					f.tryWrite(<-f.logChan)
+					_, err := f.writeData(<-f.logChan)
+					f.errs.handle(err)
 				}
-				f.writeFile()
+				_, err := f.writeFile()
+				f.errs.handle(err)
 			}()
 			for {
 				select {
@@ -144,16 +154,30 @@ func (f *BufferedRotateFile) Open(conf Config) error {
 					return
 				case d, ok := <-f.logChan:
 					if ok {
-						f.tryWrite(d)
+						_, err := f.writeData(d)
+						f.errs.handle(err)
 					}
 				case <-ticker.C:
-					f.writeFile()
+					_, err := f.writeFile()
+					f.errs.handle(err)
+				case ch := <-f.syncChan:
+					size := len(f.logChan)
+					for i := 0; i < size; i++ {
+						_, err := f.writeData(<-f.logChan)
+						f.errs.handle(err)
+					}
+					_, err := f.writeFile()
+					if err == nil {
+						err = f.file.Sync()
+					}
+					ch <- err
 				}
 				select {
 				case <-f.stopChan:
 					return
 				case <-ticker.C:
-					f.writeFile()
+					_, err := f.writeFile()
+					f.errs.handle(err)
 				default:
 				}
 			}
@@ -178,18 +202,26 @@ func (f *BufferedRotateFile) Write(data []byte) (int, error) {
 	}
 
 	if f.block {
-		f.logChan <- data
+		f.logChan <- copyBytes(data)
 		return len(data), nil
 	} else {
+		d := copyBytes(data)
 		select {
-		case f.logChan <- data:
+		case f.logChan <- d:
 			return len(data), nil
 		default:
+			putBytes(d)
 			return 0, errors.New("write log failed ")
 		}
 	}
 }
 
+func (f *BufferedRotateFile) writeData(data *[]byte) (int, error) {
+	n, err := f.tryWrite(*data)
+	putBytes(data)
+	return n, err
+}
+
 func (f *BufferedRotateFile) tryWrite(data []byte) (int, error) {
 	if len(data) == 0 {
 		return 0, nil
@@ -329,6 +361,21 @@ func (f *BufferedRotateFile) nextTime() time.Time {
 	return t.Add(f.rotateDuration)
 }
 
+// 将调用之前已接收的数据写入文件并同步（线程安全）
+func (f *BufferedRotateFile) Sync() error {
+	return requestSync(f.syncChan, f.stopChan)
+}
+
+// 设置写入失败的处理函数（线程安全）
+func (f *BufferedRotateFile) SetErrorHandler(h ErrorHandler) {
+	f.errs.setHandler(h)
+}
+
+// 获得写入失败的次数（线程安全）
+func (f *BufferedRotateFile) Errors() uint64 {
+	return f.errs.errors()
+}
+
 func (f *BufferedRotateFile) Close() error {
 	f.once.Do(func() {
 		close(f.stopChan)
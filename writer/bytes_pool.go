@@ -0,0 +1,30 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package writer
+
+import "sync"
+
+// 超出该容量的缓存不放回池中
+const maxPooledBytesSize = 64 << 10
+
+var bytesPool = sync.Pool{New: func() interface{} {
+	b := make([]byte, 0, 512)
+	return &b
+}}
+
+// 异步Writer的Write返回后调用者可能复用数据（如xlog使用池化的缓存格式化日志），需复制后再交给写入协程
+func copyBytes(data []byte) *[]byte {
+	b := bytesPool.Get().(*[]byte)
+	*b = append((*b)[:0], data...)
+	return b
+}
+
+func putBytes(b *[]byte) {
+	if cap(*b) > maxPooledBytesSize {
+		return
+	}
+	bytesPool.Put(b)
+}
@@ -0,0 +1,37 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package writer
+
+import (
+	"sync/atomic"
+)
+
+// 写入失败的处理函数，异步Writer在写入协程中调用，不应阻塞
+type ErrorHandler func(err error)
+
+// 写入失败的统计及处理，线程安全
+type errorCounter struct {
+	count   uint64
+	handler atomic.Value
+}
+
+func (c *errorCounter) setHandler(h ErrorHandler) {
+	c.handler.Store(h)
+}
+
+func (c *errorCounter) handle(err error) {
+	if err == nil {
+		return
+	}
+	atomic.AddUint64(&c.count, 1)
+	if h, ok := c.handler.Load().(ErrorHandler); ok && h != nil {
+		h(err)
+	}
+}
+
+func (c *errorCounter) errors() uint64 {
+	return atomic.LoadUint64(&c.count)
+}
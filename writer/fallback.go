@@ -0,0 +1,116 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package writer
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// 故障转移后探测主Writer是否恢复的默认时间间隔
+var DefaultProbeInterval = 30 * time.Second
+
+type FallbackWriter struct {
+	lock          sync.Mutex
+	writers       []io.Writer
+	cur           int
+	failTime      time.Time
+	probeInterval time.Duration
+
+	errs errorCounter
+}
+
+// 故障转移的Writer，本身Write、Close方法线程安全：
+// 优先写入primary，写入失败时按顺序写入下一个secondary（如os.Stderr、本地文件），
+// 故障转移后每隔探测间隔（参见SetProbeInterval）使用写入的数据探测之前的Writer，写入成功则切换回去。
+// 所有Writer都写入失败时返回最后一个错误，每次失败都会交给ErrorHandler处理
+func Fallback(primary io.Writer, secondary ...io.Writer) *FallbackWriter {
+	return &FallbackWriter{
+		writers:       append([]io.Writer{primary}, secondary...),
+		probeInterval: DefaultProbeInterval,
+	}
+}
+
+// 设置探测之前Writer的时间间隔（线程安全）
+func (w *FallbackWriter) SetProbeInterval(interval time.Duration) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.probeInterval = interval
+}
+
+// 设置写入失败的处理函数（线程安全）
+func (w *FallbackWriter) SetErrorHandler(h ErrorHandler) {
+	w.errs.setHandler(h)
+}
+
+// 获得写入失败的次数（线程安全）
+func (w *FallbackWriter) Errors() uint64 {
+	return w.errs.errors()
+}
+
+// 获得当前写入的Writer序号，0为primary（线程安全）
+func (w *FallbackWriter) Current() int {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	return w.cur
+}
+
+func (w *FallbackWriter) Write(data []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	start := w.cur
+	if start > 0 && time.Since(w.failTime) >= w.probeInterval {
+		start = 0
+	}
+	var err error
+	for i := start; i < len(w.writers); i++ {
+		var n int
+		n, err = w.writers[i].Write(data)
+		if err == nil {
+			w.cur = i
+			return n, nil
+		}
+		w.errs.handle(err)
+		// 故障转移或探测失败，重新计时
+		w.failTime = time.Now()
+	}
+	if err == nil {
+		err = errors.New("No writer available ")
+	}
+	return 0, err
+}
+
+// 同步当前写入的Writer（如果实现了Syncer）
+func (w *FallbackWriter) Sync() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	return syncWriter(w.writers[w.cur])
+}
+
+// 关闭所有实现了io.Closer的Writer（os.Stdout及os.Stderr除外），返回第一个错误
+func (w *FallbackWriter) Close() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	var ret error
+	for _, v := range w.writers {
+		if isConsole(v) {
+			continue
+		}
+		if c, ok := v.(io.Closer); ok {
+			if err := c.Close(); err != nil && ret == nil {
+				ret = err
+			}
+		}
+	}
+	return ret
+}
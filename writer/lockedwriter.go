@@ -24,6 +24,13 @@ func (lw *LockedWriter) Write(d []byte) (int, error) {
 	return lw.W.Write(d)
 }
 
+func (lw *LockedWriter) Sync() error {
+	lw.lock.Lock()
+	defer lw.lock.Unlock()
+
+	return syncWriter(lw.W)
+}
+
 type LockedWriteCloser struct {
 	lock sync.Mutex
 	W    io.WriteCloser
@@ -42,3 +49,10 @@ func (lw *LockedWriteCloser) Close() error {
 
 	return lw.W.Close()
 }
+
+func (lw *LockedWriteCloser) Sync() error {
+	lw.lock.Lock()
+	defer lw.lock.Unlock()
+
+	return syncWriter(lw.W)
+}
@@ -0,0 +1,272 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package writer
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NewRing的maxEntries及maxBytes都小于等于0时默认保存的日志条数
+var DefaultRingMaxEntries = 1000
+
+// JSON格式日志中日志级别及日志名称的Key，与xlog.KeySeverityLevel、xlog.KeyName相同
+var (
+	RingLevelKey = "LogLevel"
+	RingNameKey  = "LogName"
+)
+
+// 环形缓存中的日志条目
+type RingEntry struct {
+	// 写入时间
+	Time time.Time
+	// 格式化后的日志
+	Data []byte
+}
+
+// 环形缓存的查询条件，条件为空时不过滤，多个条件同时满足时匹配
+type RingQuery struct {
+	// 日志级别（不区分大小写），仅对JSON格式的日志有效
+	Levels []string
+	// 日志名称，同时匹配子日志（如"db"匹配"db.pool"），仅对JSON格式的日志有效
+	Names []string
+	// 不早于该时间写入的日志
+	Since time.Time
+	// 包含该字符串的日志
+	Contains string
+}
+
+type Ring struct {
+	lock       sync.Mutex
+	entries    []RingEntry
+	head       int
+	count      int
+	size       int
+	maxEntries int
+	maxBytes   int
+	crashFile  string
+}
+
+// 在内存中保存最近写入的日志的Writer，线程安全：
+// 每次Write为一条日志，超出maxEntries条或maxBytes字节时丢弃最早的日志，小于等于0时不限制，
+// 都小于等于0时保存最近DefaultRingMaxEntries条。
+// 可配合SetOutputBySeverity保存低级别的日志，在出现问题时通过Snapshot、Query查看或通过Dump、DumpFile输出
+func NewRing(maxEntries, maxBytes int) *Ring {
+	if maxEntries <= 0 && maxBytes <= 0 {
+		maxEntries = DefaultRingMaxEntries
+	}
+	ret := &Ring{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+	}
+	if maxEntries > 0 {
+		ret.entries = make([]RingEntry, maxEntries)
+	}
+	return ret
+}
+
+// 复制并保存数据
+func (r *Ring) Write(data []byte) (int, error) {
+	d := make([]byte, len(data))
+	copy(d, data)
+	e := RingEntry{Time: time.Now(), Data: d}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.maxEntries > 0 {
+		if r.count == r.maxEntries {
+			r.pop()
+		}
+		r.entries[(r.head+r.count)%len(r.entries)] = e
+	} else {
+		if r.head > 0 && r.head >= len(r.entries)/2 {
+			// 已丢弃的空间超过一半时整理
+			n := copy(r.entries, r.entries[r.head:])
+			for i := n; i < len(r.entries); i++ {
+				r.entries[i] = RingEntry{}
+			}
+			r.entries = r.entries[:n]
+			r.head = 0
+		}
+		r.entries = append(r.entries, e)
+	}
+	r.count++
+	r.size += len(d)
+	for r.maxBytes > 0 && r.size > r.maxBytes && r.count > 1 {
+		r.pop()
+	}
+	return len(data), nil
+}
+
+func (r *Ring) pop() {
+	r.size -= len(r.entries[r.head].Data)
+	r.entries[r.head] = RingEntry{}
+	r.head++
+	if r.maxEntries > 0 {
+		r.head %= len(r.entries)
+	}
+	r.count--
+}
+
+// 获得保存的日志条数
+func (r *Ring) Len() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.count
+}
+
+// 获得保存的日志字节数
+func (r *Ring) Size() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.size
+}
+
+// 清空保存的日志
+func (r *Ring) Reset() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for i := range r.entries {
+		r.entries[i] = RingEntry{}
+	}
+	if r.maxEntries <= 0 {
+		r.entries = r.entries[:0]
+	}
+	r.head = 0
+	r.count = 0
+	r.size = 0
+}
+
+// 获得保存的所有日志，按写入顺序排列，返回的日志条目不会被修改
+func (r *Ring) Snapshot() []RingEntry {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	ret := make([]RingEntry, 0, r.count)
+	r.each(func(e RingEntry) {
+		ret = append(ret, e)
+	})
+	return ret
+}
+
+// 获得匹配查询条件的日志，按写入顺序排列
+func (r *Ring) Query(q RingQuery) []RingEntry {
+	var ret []RingEntry
+	for _, e := range r.Snapshot() {
+		if q.match(e) {
+			ret = append(ret, e)
+		}
+	}
+	return ret
+}
+
+// 将保存的所有日志按写入顺序写入w
+func (r *Ring) Dump(w io.Writer) error {
+	for _, e := range r.Snapshot() {
+		if _, err := w.Write(e.Data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 将保存的所有日志写入文件，文件已存在时覆盖，可用于崩溃时或按需输出
+func (r *Ring) DumpFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = r.Dump(f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// 配置崩溃时输出保存日志的文件，为空时不输出。
+// Ring注册到xlog后（SetOutput、SetOutputBySeverity自动注册，或使用xlog.RegisterWriter），
+// 发生FATAL日志时调用OnCrash输出到该文件。PANIC日志可能被recover因此不输出，
+// 需要时在recover后调用DumpFile，或使用Logger.Recover的xlog.RecoverExit模式（以FATAL级别输出）
+func (r *Ring) SetCrashDumpFile(path string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.crashFile = path
+}
+
+// 实现xlog.CrashHandler，将保存的所有日志写入SetCrashDumpFile配置的文件
+func (r *Ring) OnCrash() error {
+	r.lock.Lock()
+	path := r.crashFile
+	r.lock.Unlock()
+
+	if path == "" {
+		return nil
+	}
+	return r.DumpFile(path)
+}
+
+func (r *Ring) each(f func(e RingEntry)) {
+	for i := 0; i < r.count; i++ {
+		idx := r.head + i
+		if r.maxEntries > 0 {
+			idx %= len(r.entries)
+		}
+		f(r.entries[idx])
+	}
+}
+
+func (q *RingQuery) match(e RingEntry) bool {
+	if !q.Since.IsZero() && e.Time.Before(q.Since) {
+		return false
+	}
+	if q.Contains != "" && !bytes.Contains(e.Data, []byte(q.Contains)) {
+		return false
+	}
+	if len(q.Levels) == 0 && len(q.Names) == 0 {
+		return true
+	}
+
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(bytes.TrimSpace(e.Data), &fields); err != nil {
+		return false
+	}
+	if len(q.Levels) > 0 {
+		lv, _ := fields[RingLevelKey].(string)
+		if !matchAny(q.Levels, func(v string) bool {
+			return strings.EqualFold(v, lv)
+		}) {
+			return false
+		}
+	}
+	if len(q.Names) > 0 {
+		name, _ := fields[RingNameKey].(string)
+		if !matchAny(q.Names, func(v string) bool {
+			return name == v || strings.HasPrefix(name, v+".")
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchAny(values []string, f func(v string) bool) bool {
+	for _, v := range values {
+		if f(v) {
+			return true
+		}
+	}
+	return false
+}
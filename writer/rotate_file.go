@@ -14,6 +14,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -38,6 +39,9 @@ func NewRotateFileWriter(f *RotateFile, conf ...Config) io.WriteCloser {
 
 	err := f.Open()
 	if err != nil {
+		if len(conf) > 0 && conf[0].ErrorHandler != nil {
+			conf[0].ErrorHandler(err)
+		}
 		return nil
 	}
 
@@ -66,6 +70,8 @@ type RotateFile struct {
 	curSize    int64
 	part       int
 	curTimeStr string
+	// 保护滚动时切换的文件，Sync及Close可能在其他协程调用（如xlog.Sync）
+	lock sync.Mutex
 }
 
 func (f *RotateFile) Open() error {
@@ -118,6 +124,9 @@ func (f *RotateFile) Write(data []byte) (int, error) {
 	if len(data) == 0 {
 		return 0, nil
 	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
 	if f.timer != nil {
 		select {
 		case <-f.timer.C:
@@ -232,7 +241,21 @@ func (f *RotateFile) nextTime() time.Time {
 	return t.Add(f.rotateDuration)
 }
 
+// 同步文件（线程安全）
+func (f *RotateFile) Sync() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.file != nil {
+		return f.file.Sync()
+	}
+	return nil
+}
+
 func (f *RotateFile) Close() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
 	if f.timer != nil {
 		f.timer.Stop()
 	}
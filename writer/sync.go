@@ -0,0 +1,52 @@
+// Copyright (C) 2019-2020, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package writer
+
+import (
+	"errors"
+	"io"
+	"os"
+	"syscall"
+)
+
+// 可将缓存的数据同步写入的Writer
+type Syncer interface {
+	// 将缓存的数据写入底层Writer，并同步底层Writer（如果实现了Syncer），返回时数据已写入
+	Sync() error
+}
+
+var errClosed = errors.New("writer is closed")
+
+// 同步参数Writer（如果实现了Syncer），控制台及不支持同步的文件（如管道）不同步
+func syncWriter(w io.Writer) error {
+	if isConsole(w) {
+		return nil
+	}
+	if s, ok := w.(Syncer); ok {
+		err := s.Sync()
+		if errors.Is(err, syscall.EINVAL) || errors.Is(err, syscall.ENOTSUP) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// 向写入协程发送同步请求并等待结果
+func requestSync(syncChan chan chan error, stopChan <-chan struct{}) error {
+	ch := make(chan error, 1)
+	select {
+	case syncChan <- ch:
+		return <-ch
+	case <-stopChan:
+		return errClosed
+	}
+}
+
+// 是否为进程的标准输出或标准错误，不应被同步或关闭
+func isConsole(w io.Writer) bool {
+	return w == os.Stdout || w == os.Stderr
+}